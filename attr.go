@@ -0,0 +1,58 @@
+package gadget
+
+// OTelAttributes gets the OpenTelemetry semantic convention attributes:
+//
+//	user_agent.original       uaHeader
+//	user_agent.name           BrowserName
+//	user_agent.version        BrowserVersion
+//	os.name                   OSName
+//	os.version                OSVersion
+//	device.model.name         Model
+//
+// Attributes with an empty value are omitted. For example, to add them to a
+// span:
+//
+//	for k, v := range ua.OTelAttributes(r.Header.Get("User-Agent")) {
+//		span.SetAttributes(attribute.String(k, v))
+//	}
+func (u UserAgent) OTelAttributes(uaHeader string) map[string]string {
+	return attrMap(
+		"user_agent.original", uaHeader,
+		"user_agent.name", u.BrowserName,
+		"user_agent.version", u.BrowserVersion,
+		"os.name", u.OSName,
+		"os.version", u.OSVersion,
+		"device.model.name", u.Model)
+}
+
+// ECSFields gets the Elastic Common Schema fields:
+//
+//	user_agent.original       uaHeader
+//	user_agent.name           BrowserName
+//	user_agent.version        BrowserVersion
+//	user_agent.os.name        OSName
+//	user_agent.os.version     OSVersion
+//	user_agent.os.full        OS()
+//	user_agent.device.name    Model
+//
+// Fields with an empty value are omitted.
+func (u UserAgent) ECSFields(uaHeader string) map[string]string {
+	return attrMap(
+		"user_agent.original", uaHeader,
+		"user_agent.name", u.BrowserName,
+		"user_agent.version", u.BrowserVersion,
+		"user_agent.os.name", u.OSName,
+		"user_agent.os.version", u.OSVersion,
+		"user_agent.os.full", u.OS(),
+		"user_agent.device.name", u.Model)
+}
+
+func attrMap(kv ...string) map[string]string {
+	m := make(map[string]string, len(kv)/2)
+	for i := 0; i < len(kv); i += 2 {
+		if kv[i+1] != "" {
+			m[kv[i]] = kv[i+1]
+		}
+	}
+	return m
+}
@@ -0,0 +1,43 @@
+package gadget
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestOTelAttributes(t *testing.T) {
+	const uaHeader = "Mozilla/5.0 (Windows NT 10.0; Win64; x64; rv:73.0) Gecko/20100101 Firefox/73.0"
+	ua := ParseUA(uaHeader)
+	ua.Model = "x"
+
+	got := ua.OTelAttributes(uaHeader)
+	want := map[string]string{
+		"user_agent.original": uaHeader,
+		"user_agent.name":     "Firefox",
+		"user_agent.version":  "73",
+		"os.name":             "Windows",
+		"os.version":          "10",
+		"device.model.name":   "x",
+	}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("\ngot:  %#v\nwant: %#v", got, want)
+	}
+
+	got = ua.ECSFields(uaHeader)
+	want = map[string]string{
+		"user_agent.original":    uaHeader,
+		"user_agent.name":        "Firefox",
+		"user_agent.version":     "73",
+		"user_agent.os.name":     "Windows",
+		"user_agent.os.version":  "10",
+		"user_agent.os.full":     "Windows 10",
+		"user_agent.device.name": "x",
+	}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("\ngot:  %#v\nwant: %#v", got, want)
+	}
+
+	if got := (UserAgent{}).OTelAttributes(""); len(got) != 0 {
+		t.Errorf("not empty: %#v", got)
+	}
+}
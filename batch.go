@@ -0,0 +1,205 @@
+package gadget
+
+import (
+	"context"
+	"runtime"
+	"sync"
+	"time"
+)
+
+const (
+	// Number of headers ParseAll() parses in one go.
+	batchSize = 512
+
+	// How long ParseAll() waits for a batch to fill up after the first header.
+	batchWait = time.Millisecond
+)
+
+// Result is a parsed User-Agent header from ParseAll().
+type Result struct {
+	Index     int    // Position in the input, starting at 0.
+	Header    string // The User-Agent header.
+	UserAgent UserAgent
+}
+
+type batch struct {
+	seq     int
+	start   int
+	headers []string
+	uas     []UserAgent
+}
+
+// ParseAll parses all User-Agent headers from in, with the given number of
+// workers; if workers is 0 or lower it will use GOMAXPROCS. The returned
+// channel is closed after in is closed and all headers have been parsed, or
+// when ctx is cancelled.
+//
+// Headers are read in batches of up to 512, and identical headers in a batch
+// are only parsed once. A batch is handed to the workers once it's full, or 1ms
+// after its first header was read. If ordered is true the results will be sent
+// in the same order as the input, otherwise they're sent as soon as a batch is
+// done.
+//
+// in should be buffered, ideally with a capacity of 512 or more; batches will
+// be small and parsing less efficient if the sender can't keep up.
+func ParseAll(ctx context.Context, in <-chan string, workers int, ordered bool) <-chan Result {
+	if workers < 1 {
+		workers = runtime.GOMAXPROCS(0)
+	}
+
+	var (
+		out     = make(chan Result, batchSize)
+		todo    = make(chan *batch, workers)
+		done    = make(chan *batch, workers)
+		pending = make(chan struct{}, workers*2) // Limit the number of batches in memory.
+		wg      sync.WaitGroup
+	)
+
+	// Read batches.
+	go func() {
+		defer close(todo)
+		var buf [batchSize]string
+		for seq, start := 0, 0; ; seq++ {
+			select {
+			case pending <- struct{}{}:
+			case <-ctx.Done():
+				return
+			}
+
+			b := readBatch(ctx, in, &buf)
+			if len(b.headers) == 0 {
+				return
+			}
+			b.seq, b.start = seq, start
+			start += len(b.headers)
+
+			select {
+			case todo <- b:
+			case <-ctx.Done():
+				return
+			}
+		}
+	}()
+
+	// Parse them.
+	wg.Add(workers)
+	for i := 0; i < workers; i++ {
+		go func() {
+			defer wg.Done()
+			for b := range todo {
+				b.parse()
+				select {
+				case done <- b:
+				case <-ctx.Done():
+					return
+				}
+			}
+		}()
+	}
+	go func() {
+		wg.Wait()
+		close(done)
+	}()
+
+	// Send the results.
+	go func() {
+		defer close(out)
+		var (
+			next    = 0
+			waiting = make(map[int]*batch)
+		)
+		for b := range done {
+			if !ordered {
+				if !b.send(ctx, out) {
+					return
+				}
+				<-pending
+				continue
+			}
+
+			waiting[b.seq] = b
+			for {
+				b, ok := waiting[next]
+				if !ok {
+					break
+				}
+				delete(waiting, next)
+				if !b.send(ctx, out) {
+					return
+				}
+				<-pending
+				next++
+			}
+		}
+	}()
+
+	return out
+}
+
+// Read up to batchSize headers in to buf; this blocks until at least one
+// header is read, and then waits at most batchWait for the batch to fill up.
+//
+// The headers are copied from buf, so a batch only uses as much memory as the
+// number of headers that were read.
+func readBatch(ctx context.Context, in <-chan string, buf *[batchSize]string) *batch {
+	n := 0
+	select {
+	case h, ok := <-in:
+		if !ok {
+			return &batch{}
+		}
+		buf[n] = h
+		n++
+	case <-ctx.Done():
+		return &batch{}
+	}
+
+	t := time.NewTimer(batchWait)
+	defer t.Stop()
+fill:
+	for n < batchSize {
+		select {
+		case h, ok := <-in:
+			if !ok {
+				break fill
+			}
+			buf[n] = h
+			n++
+		case <-t.C:
+			break fill
+		case <-ctx.Done():
+			break fill
+		}
+	}
+
+	b := &batch{headers: make([]string, n)}
+	copy(b.headers, buf[:n])
+	for i := range buf[:n] { // Don't keep references to old headers.
+		buf[i] = ""
+	}
+	return b
+}
+
+func (b *batch) parse() {
+	b.uas = make([]UserAgent, len(b.headers))
+	seen := make(map[string]int, len(b.headers))
+	for i, h := range b.headers {
+		if j, ok := seen[h]; ok {
+			b.uas[i] = b.uas[j]
+			continue
+		}
+		seen[h] = i
+		b.uas[i] = ParseUA(h)
+	}
+}
+
+func (b *batch) send(ctx context.Context, out chan<- Result) bool {
+	for i := range b.headers {
+		select {
+		case out <- Result{Index: b.start + i, Header: b.headers[i], UserAgent: b.uas[i]}:
+		case <-ctx.Done():
+			return false
+		}
+	}
+	return true
+}
@@ -0,0 +1,116 @@
+package gadget
+
+import (
+	"context"
+	"sort"
+	"testing"
+	"time"
+)
+
+func TestParseAll(t *testing.T) {
+	var list []string
+	for i := 0; i < 5; i++ { // Lots of duplicates, and more than one batch.
+		list = append(list, testHeaders(t, "top500")...)
+	}
+
+	for _, ordered := range []bool{true, false} {
+		in := make(chan string)
+		go func() {
+			defer close(in)
+			for _, h := range list {
+				in <- h
+			}
+		}()
+
+		var got []Result
+		for r := range ParseAll(context.Background(), in, 4, ordered) {
+			got = append(got, r)
+		}
+		if len(got) != len(list) {
+			t.Fatalf("ordered=%t: got %d results; want %d", ordered, len(got), len(list))
+		}
+
+		isSorted := sort.SliceIsSorted(got, func(i, j int) bool { return got[i].Index < got[j].Index })
+		if ordered && !isSorted {
+			t.Errorf("ordered=%t: results not in order", ordered)
+		}
+		sort.Slice(got, func(i, j int) bool { return got[i].Index < got[j].Index })
+
+		for i, r := range got {
+			if r.Index != i || r.Header != list[i] {
+				t.Fatalf("ordered=%t: result %d: wrong index or header: %d %q", ordered, i, r.Index, r.Header)
+			}
+			if want := ParseUA(list[i]); r.UserAgent != want {
+				t.Errorf("ordered=%t: %q\ngot:  %#v\nwant: %#v", ordered, r.Header, r.UserAgent, want)
+			}
+		}
+	}
+}
+
+func TestParseAllCancel(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	in := make(chan string) // Never closed.
+	out := ParseAll(ctx, in, 2, true)
+
+	in <- "Mozilla/5.0 (X11; Linux x86_64; rv:78.0) Gecko/20100101 Firefox/78.0"
+	if r := <-out; r.UserAgent.Browser() != "Firefox 78" {
+		t.Errorf("wrong result: %#v", r)
+	}
+
+	cancel()
+	select {
+	case _, ok := <-out:
+		if ok {
+			t.Error("received after cancel")
+		}
+	case <-time.After(5 * time.Second):
+		t.Fatal("channel not closed after cancel")
+	}
+}
+
+func TestReadBatch(t *testing.T) {
+	var buf [batchSize]string
+
+	// Read from an unbuffered channel until it's closed; the batches should
+	// only allocate what they need.
+	in := make(chan string)
+	go func() {
+		defer close(in)
+		for i := 0; i < 100; i++ {
+			in <- "x"
+		}
+	}()
+	for total := 0; total < 100; {
+		b := readBatch(context.Background(), in, &buf)
+		if len(b.headers) == 0 || cap(b.headers) != len(b.headers) {
+			t.Fatalf("len %d, cap %d", len(b.headers), cap(b.headers))
+		}
+		total += len(b.headers)
+	}
+
+	// Don't wait forever for more.
+	in = make(chan string, 1) // Never closed.
+	in <- "x"
+	if b := readBatch(context.Background(), in, &buf); len(b.headers) != 1 {
+		t.Errorf("len %d; want 1", len(b.headers))
+	}
+	if buf[0] != "" {
+		t.Errorf("buf not cleared: %q", buf[0])
+	}
+}
+
+func BenchmarkParseAll(b *testing.B) {
+	list := testHeaders(b, "top500")
+	b.ReportAllocs()
+	b.ResetTimer()
+
+	in := make(chan string, batchSize)
+	go func() {
+		defer close(in)
+		for n := 0; n < b.N; n++ {
+			in <- list[n%len(list)]
+		}
+	}()
+	for range ParseAll(context.Background(), in, 0, false) {
+	}
+}
@@ -0,0 +1,126 @@
+package gadget
+
+import (
+	"bufio"
+	"container/list"
+	"io"
+	"strings"
+	"sync"
+)
+
+// CachedParser parses User-Agent headers with a bounded LRU cache of the
+// results. It's safe for concurrent use.
+type CachedParser struct {
+	mu    sync.Mutex
+	size  int
+	ll    *list.List
+	items map[string]*list.Element
+	stats CacheStats
+}
+
+// CacheStats are the statistics for a CachedParser.
+type CacheStats struct {
+	Hits      uint64 // Number of lookups that were in the cache.
+	Misses    uint64 // Number of lookups that weren't in the cache.
+	Evictions uint64 // Number of entries removed to make room for new ones.
+	Len       int    // Current number of entries.
+}
+
+type cacheEntry struct {
+	key string
+	ua  UserAgent
+}
+
+// NewCachedParser creates a new CachedParser that stores at most size
+// results.
+func NewCachedParser(size int) *CachedParser {
+	if size < 1 {
+		size = 1
+	}
+	return &CachedParser{
+		size:  size,
+		ll:    list.New(),
+		items: make(map[string]*list.Element, size),
+	}
+}
+
+// ParseUA parses a User-Agent header, using the cached result if there is one.
+func (c *CachedParser) ParseUA(uaHeader string) UserAgent {
+	c.mu.Lock()
+	if e, ok := c.items[uaHeader]; ok {
+		c.ll.MoveToFront(e)
+		c.stats.Hits++
+		ua := e.Value.(*cacheEntry).ua
+		c.mu.Unlock()
+		return ua
+	}
+	c.stats.Misses++
+	c.mu.Unlock()
+
+	// Copy, so we don't keep a reference to a larger buffer this may be a
+	// part of.
+	uaHeader = cloneString(uaHeader)
+	ua := ParseUA(uaHeader)
+	c.add(uaHeader, ua)
+	return ua
+}
+
+// Stats gets the cache statistics.
+func (c *CachedParser) Stats() CacheStats {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	s := c.stats
+	s.Len = c.ll.Len()
+	return s
+}
+
+// Warm the cache from a corpus of User-Agent headers, one per line. This
+// doesn't count as hits or misses.
+//
+// Lines starting with # are skipped. If a line contains tabs only the last
+// column is used. If shortened is true every line is expanded with
+// UnshortenUA(), so files in the same format as testdata/top500 can be used;
+// don't use this for raw headers, as it would change any header with a "~".
+func (c *CachedParser) Warm(r io.Reader, shortened bool) error {
+	scanner := bufio.NewScanner(r)
+	for scanner.Scan() {
+		line := scanner.Text()
+		if line == "" || line[0] == '#' {
+			continue
+		}
+		if i := strings.LastIndexByte(line, '\t'); i > -1 {
+			line = line[i+1:]
+		}
+		if shortened {
+			line = UnshortenUA(line)
+		}
+		c.add(line, ParseUA(line))
+	}
+	return scanner.Err()
+}
+
+func (c *CachedParser) add(uaHeader string, ua UserAgent) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	// Another goroutine may have added it since we checked.
+	if e, ok := c.items[uaHeader]; ok {
+		c.ll.MoveToFront(e)
+		return
+	}
+
+	c.items[uaHeader] = c.ll.PushFront(&cacheEntry{key: uaHeader, ua: ua})
+	for c.ll.Len() > c.size {
+		e := c.ll.Back()
+		c.ll.Remove(e)
+		delete(c.items, e.Value.(*cacheEntry).key)
+		c.stats.Evictions++
+	}
+}
+
+// Copy s to a new allocation, so that it doesn't keep a larger string alive.
+func cloneString(s string) string {
+	var b strings.Builder
+	b.WriteString(s)
+	return b.String()
+}
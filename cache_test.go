@@ -0,0 +1,97 @@
+package gadget
+
+import (
+	"fmt"
+	"os"
+	"strings"
+	"sync"
+	"testing"
+)
+
+func TestCachedParser(t *testing.T) {
+	c := NewCachedParser(2)
+
+	var (
+		ff     = "Mozilla/5.0 (Windows NT 10.0; Win64; x64; rv:73.0) Gecko/20100101 Firefox/73.0"
+		chrome = "Mozilla/5.0 (Windows NT 10.0; Win64; x64) AppleWebKit/537.36 (KHTML, like Gecko) Chrome/80.0.3987.132 Safari/537.36"
+		safari = "Mozilla/5.0 (Macintosh; Intel Mac OS X 10_15_3) AppleWebKit/605.1.15 (KHTML, like Gecko) Version/13.0.5 Safari/605.1.15"
+	)
+	for _, ua := range []string{ff, ff, chrome, ff, safari, chrome} {
+		if got, want := c.ParseUA(ua), ParseUA(ua); got != want {
+			t.Errorf("\ngot:  %s\nwant: %s", got, want)
+		}
+	}
+
+	// ff, ff (hit), chrome, ff (hit), safari (evicts chrome), chrome (evicts ff)
+	want := CacheStats{Hits: 2, Misses: 4, Evictions: 2, Len: 2}
+	if got := c.Stats(); got != want {
+		t.Errorf("\ngot:  %+v\nwant: %+v", got, want)
+	}
+}
+
+func TestCachedParserConcurrent(t *testing.T) {
+	c := NewCachedParser(10)
+	var wg sync.WaitGroup
+	for i := 0; i < 8; i++ {
+		wg.Add(1)
+		go func(i int) {
+			defer wg.Done()
+			for j := 0; j < 100; j++ {
+				c.ParseUA(fmt.Sprintf("Firefox/%d.0", (i+j)%20))
+			}
+		}(i)
+	}
+	wg.Wait()
+
+	s := c.Stats()
+	if s.Hits+s.Misses != 800 || s.Len != 10 {
+		t.Errorf("wrong stats: %+v", s)
+	}
+}
+
+func TestCachedParserWarm(t *testing.T) {
+	fp, err := os.Open("./testdata/top500")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer fp.Close()
+
+	c := NewCachedParser(1000)
+	if err := c.Warm(fp, true); err != nil {
+		t.Fatal(err)
+	}
+	if s := c.Stats(); s.Len < 400 || s.Hits != 0 || s.Misses != 0 {
+		t.Fatalf("wrong stats after warming: %+v", s)
+	}
+
+	c.ParseUA("Mozilla/5.0 (Windows NT 10.0; Win64; x64) AppleWebKit/537.36 (KHTML, like Gecko) Chrome/79.0.3945.88 Safari/537.36")
+	if s := c.Stats(); s.Hits != 1 {
+		t.Errorf("not a hit: %+v", s)
+	}
+
+	err = NewCachedParser(1).Warm(strings.NewReader("# comment\n\ncurl/7.0\n"), false)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// Raw headers with a "~" shouldn't be changed.
+	const tilde = "Mozilla/5.0 (X11; Linux x86_64) ~Z~f/7.0 Firefox/120.0"
+	c = NewCachedParser(10)
+	if err := c.Warm(strings.NewReader(tilde+"\n"), false); err != nil {
+		t.Fatal(err)
+	}
+	c.ParseUA(tilde)
+	if s := c.Stats(); s.Hits != 1 || s.Len != 1 {
+		t.Errorf("not a hit: %+v", s)
+	}
+}
+
+func BenchmarkCachedParser(b *testing.B) {
+	list := testHeaders(b, "top500")
+	c := NewCachedParser(1000)
+	b.ReportAllocs()
+	b.ResetTimer()
+	for n := 0; n < b.N; n++ {
+		c.ParseUA(list[n%len(list)])
+	}
+}
@@ -0,0 +1,189 @@
+package gadget
+
+import (
+	"encoding/binary"
+	"fmt"
+	"strconv"
+	"strings"
+)
+
+// The binary format from EncodeUA() is a byte with the dictionary version (the
+// same as ShortenUA() uses), followed by:
+//
+//	0x00 b            Literal byte b, for bytes that can't be stored as-is.
+//	0x01-0x7f         Literal ASCII.
+//	0x80-0xef         Dictionary token; 0x80 is the first token in the
+//	                  dictionary, not counting the "~" escape.
+//	0xf0 n varint...  n numbers separated by dots, such as "537.36"; the
+//	                  numbers and n are unsigned varints.
+//	0xf1-0xff         Reserved.
+const (
+	encEscape = 0x00
+	encToken  = 0x80
+	encNumber = 0xf0
+)
+
+// Tokens for EncodeUA(), indexed by the first byte.
+var encodeIndex = func() [][256][]int {
+	idx := make([][256][]int, len(shortDicts))
+	for v, d := range shortDicts {
+		if d == nil {
+			continue
+		}
+		for i, t := range d.tokens[1:] {
+			if i >= encNumber-encToken {
+				panic(fmt.Sprintf("gadget: too many tokens in version %d for EncodeUA", v))
+			}
+			idx[v][t.long[0]] = append(idx[v][t.long[0]], i)
+		}
+	}
+	return idx
+}()
+
+// EncodeUA encodes a User-Agent header in a compact binary format, using the
+// latest version of the ShortenUA() dictionary.
+//
+// This is smaller than ShortenUA(), at the expense of readability: on
+// testdata/top500 it's 37% of the original size, compared to 58% for
+// ShortenUA(). Use DecodeUA() to reverse it; decoding always gives back the
+// exact header.
+func EncodeUA(uaHeader string) []byte {
+	var (
+		v   = len(shortDicts) - 1
+		d   = shortDicts[v]
+		b   = make([]byte, 0, len(uaHeader)/2+8)
+		num [2 + binary.MaxVarintLen64*8]byte
+	)
+	b = append(b, byte(v))
+
+outer:
+	for len(uaHeader) > 0 {
+		c := uaHeader[0]
+		for _, i := range encodeIndex[v][c] {
+			if t := d.tokens[i+1].long; strings.HasPrefix(uaHeader, t) {
+				b = append(b, byte(encToken+i))
+				uaHeader = uaHeader[len(t):]
+				continue outer
+			}
+		}
+
+		if isNumber(c) {
+			if n, l := encodeNumber(num[:0], uaHeader); len(n) < l {
+				b = append(b, n...)
+				uaHeader = uaHeader[l:]
+				continue
+			}
+		}
+
+		if c == encEscape || c >= 0x80 {
+			b = append(b, encEscape)
+		}
+		b = append(b, c)
+		uaHeader = uaHeader[1:]
+	}
+	return b
+}
+
+// Encode the dotted numbers at the start of s; this returns the encoded bytes
+// and length of s it encoded.
+//
+// Numbers with a leading zero can't be encoded, as that would be lost. The
+// rest of the string is stored as literals, which always round-trips.
+func encodeNumber(b []byte, s string) ([]byte, int) {
+	var (
+		nums   [8]uint64
+		n      int
+		i, end int
+	)
+	for n < len(nums) {
+		j := i
+		for j < len(s) && isNumber(s[j]) {
+			j++
+		}
+		part := s[i:j]
+		if part == "" || (len(part) > 1 && part[0] == '0') {
+			break
+		}
+		x, err := strconv.ParseUint(part, 10, 64)
+		if err != nil {
+			break
+		}
+		nums[n], end = x, j
+		n++
+
+		if j+1 < len(s) && s[j] == '.' && isNumber(s[j+1]) {
+			i = j + 1
+			continue
+		}
+		break
+	}
+	if n == 0 {
+		return nil, 0
+	}
+
+	b = append(b, encNumber)
+	b = appendUvarint(b, uint64(n))
+	for _, x := range nums[:n] {
+		b = appendUvarint(b, x)
+	}
+	return b, end
+}
+
+func appendUvarint(b []byte, x uint64) []byte {
+	var buf [binary.MaxVarintLen64]byte
+	return append(b, buf[:binary.PutUvarint(buf[:], x)]...)
+}
+
+// DecodeUA decodes a User-Agent header encoded with EncodeUA().
+func DecodeUA(enc []byte) (string, error) {
+	if len(enc) == 0 {
+		return "", fmt.Errorf("gadget.DecodeUA: empty input")
+	}
+	v := int(enc[0])
+	if v < 1 || v >= len(shortDicts) || shortDicts[v] == nil {
+		return "", fmt.Errorf("gadget.DecodeUA: unknown version %d", v)
+	}
+	tokens := shortDicts[v].tokens[1:]
+
+	var b strings.Builder
+	b.Grow(len(enc) * 2)
+	for i := 1; i < len(enc); i++ {
+		c := enc[i]
+		switch {
+		case c == encEscape:
+			if i+1 >= len(enc) {
+				return "", fmt.Errorf("gadget.DecodeUA: escape at end of input")
+			}
+			i++
+			b.WriteByte(enc[i])
+		case c < encToken:
+			b.WriteByte(c)
+		case c < encNumber:
+			t := int(c - encToken)
+			if t >= len(tokens) {
+				return "", fmt.Errorf("gadget.DecodeUA: unknown token 0x%x at position %d", c, i)
+			}
+			b.WriteString(tokens[t].long)
+		case c == encNumber:
+			n, l := binary.Uvarint(enc[i+1:])
+			if l <= 0 || n == 0 || n > 8 {
+				return "", fmt.Errorf("gadget.DecodeUA: invalid number at position %d", i)
+			}
+			i += l
+			for j := uint64(0); j < n; j++ {
+				x, l := binary.Uvarint(enc[i+1:])
+				if l <= 0 {
+					return "", fmt.Errorf("gadget.DecodeUA: invalid number at position %d", i)
+				}
+				if j > 0 {
+					b.WriteByte('.')
+				}
+				b.WriteString(strconv.FormatUint(x, 10))
+				i += l
+			}
+		default:
+			return "", fmt.Errorf("gadget.DecodeUA: reserved byte 0x%x at position %d", c, i)
+		}
+	}
+	return b.String(), nil
+}
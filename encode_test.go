@@ -0,0 +1,116 @@
+package gadget
+
+import (
+	"encoding/json"
+	"fmt"
+	"math/rand"
+	"testing"
+)
+
+func TestEncodeUA(t *testing.T) {
+	tests := []string{
+		"",
+		"a",
+		"~",
+		"\x00",
+		"\x00\xff\x80\xf0",
+		"0",
+		"00",
+		"1.01",
+		"1.2.3.4.5.6.7.8.9.10",
+		"1.",
+		"Firefox/78.0",
+		"Chrome/80.0.3987.132",
+		"99999999999999999999999",
+		"18446744073709551615.18446744073709551616",
+		"Mozilla/5.0 (Windows NT 10.0; Win64; x64) AppleWebKit/537.36 (KHTML, like Gecko) Chrome/80.0.3987.132 Safari/537.36",
+		"日本語",
+	}
+
+	var naughty []string
+	if err := json.Unmarshal(naughtyStrings, &naughty); err != nil {
+		t.Fatal(err)
+	}
+	tests = append(tests, naughty...)
+
+	r := rand.New(rand.NewSource(1))
+	for i := 0; i < 1000; i++ {
+		b := make([]byte, r.Intn(50))
+		for j := range b {
+			b[j] = "0123456789.\x00\x80\xf0\xffMozila/ "[r.Intn(21)]
+		}
+		tests = append(tests, string(b))
+	}
+
+	for i, tt := range tests {
+		t.Run(fmt.Sprintf("%d", i), func(t *testing.T) {
+			enc := EncodeUA(tt)
+			got, err := DecodeUA(enc)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if got != tt {
+				t.Errorf("\ngot:  %q\nwant: %q\nenc:  %x", got, tt, enc)
+			}
+		})
+	}
+}
+
+func TestEncodeUACorpus(t *testing.T) {
+	var size [3]int
+	for _, ua := range testHeaders(t) {
+		enc := EncodeUA(ua)
+		got, err := DecodeUA(enc)
+		if err != nil {
+			t.Fatalf("%q: %s", ua, err)
+		}
+		if got != ua {
+			t.Errorf("doesn't round-trip\ngot:  %q\nwant: %q", got, ua)
+		}
+		size[0], size[1], size[2] = size[0]+len(ua), size[1]+len(ShortenUA(ua)), size[2]+len(enc)
+	}
+
+	if size[2] >= size[1] {
+		t.Errorf("not smaller than ShortenUA: %d bytes, %d bytes, %d bytes", size[0], size[1], size[2])
+	}
+}
+
+func TestDecodeUAError(t *testing.T) {
+	tests := []struct {
+		in      []byte
+		wantErr string
+	}{
+		{nil, "empty input"},
+		{[]byte{0}, "unknown version 0"},
+		{[]byte{99}, "unknown version 99"},
+		{[]byte{2, 'a', encEscape}, "escape at end of input"},
+		{[]byte{2, encToken + 0x6f}, "unknown token 0xef"},
+		{[]byte{2, encNumber}, "invalid number"},
+		{[]byte{2, encNumber, 0}, "invalid number"},
+		{[]byte{2, encNumber, 9}, "invalid number"},
+		{[]byte{2, encNumber, 2, 1}, "invalid number"},
+		{[]byte{2, encNumber, 1, 0x80}, "invalid number"},
+		{[]byte{2, 0xf1}, "reserved byte 0xf1"},
+	}
+
+	for i, tt := range tests {
+		t.Run(fmt.Sprintf("%d", i), func(t *testing.T) {
+			_, err := DecodeUA(tt.in)
+			if !errorContains(err, tt.wantErr) {
+				t.Errorf("\ngot:  %v\nwant: %v", err, tt.wantErr)
+			}
+		})
+	}
+}
+
+func BenchmarkEncodeUA(b *testing.B) {
+	list := testHeaders(b, "top500")
+	b.ReportAllocs()
+	b.ResetTimer()
+	for n := 0; n < b.N; n++ {
+		enc := EncodeUA(list[n%len(list)])
+		if _, err := DecodeUA(enc); err != nil {
+			b.Fatal(err)
+		}
+	}
+}
@@ -0,0 +1,37 @@
+package gadget
+
+import "sort"
+
+//go:generate go run gen_features.go
+
+// Supports reports if the browser supports a web feature, such as
+// "es-modules" or "webp".
+//
+// This is answered from a snapshot of browser-compat data embedded in gadget;
+// see Features() for the list of features. It returns false for unknown
+// features and browsers.
+//
+// Safari on iOS is checked against the iOS Safari data, as it supports
+// different features than Safari on macOS with the same version.
+func Supports(ua UserAgent, feature string) bool {
+	name := ua.BrowserName
+	if name == "Safari" && ua.OSName == "iOS" {
+		name = "iOS Safari"
+	}
+	min, ok := features[feature][name]
+	v := ua.BrowserVer()
+	if !ok || v.IsZero() {
+		return false
+	}
+	return !v.Less(ParseVersion(min))
+}
+
+// Features lists all features Supports() knows about.
+func Features() []string {
+	f := make([]string, 0, len(features))
+	for k := range features {
+		f = append(f, k)
+	}
+	sort.Strings(f)
+	return f
+}
@@ -0,0 +1,203 @@
+package gadget
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+	"unicode/utf8"
+)
+
+// Join shows the full browser and OS as "<browser> <on> <os>"; for example
+// ua.Join("sur") gives "Firefox 73 sur Windows 10". If either one is blank the
+// connector will be omitted.
+func (u UserAgent) Join(on string) string {
+	var buf [64]byte
+	return string(u.AppendJoin(buf[:0], on))
+}
+
+// AppendJoin appends Join(on) to b and returns the extended buffer.
+func (u UserAgent) AppendJoin(b []byte, on string) []byte {
+	hasBrowser, hasOS := u.BrowserName != "" || u.BrowserVersion != "", u.OSName != "" || u.OSVersion != ""
+	b = u.AppendBrowser(b)
+	if hasBrowser && hasOS {
+		b = append(append(append(b, ' '), on...), ' ')
+	}
+	return u.AppendOS(b)
+}
+
+// Format the UserAgent according to a template; for example:
+//
+//	gadget.Format(ua, "{browser.name} {browser.major}[ — {os}]")
+//
+// The placeholders are:
+//
+//	{browser}           Browser name and version, as Browser().
+//	{browser.name}      BrowserName.
+//	{browser.version}   BrowserVersion.
+//	{browser.major}     Major version of BrowserVersion.
+//	{os}                OS name and version, as OS().
+//	{os.name}           OSName.
+//	{os.version}        OSVersion.
+//	{os.major}          Major version of OSVersion.
+//	{model}             Model.
+//	{app}               App.
+//
+// Everything between [ and ] is removed if any of the placeholders in it are
+// empty. Sections can be nested, in which case an empty placeholder only
+// removes the innermost section it's in; for example "{browser}[ ({model}[,
+// {app}])]" omits the app if it's empty, and both the model and app if the
+// model is empty. A [ or ] without a matching bracket is shown as-is.
+//
+// Use {{, }}, [[, and ]] for literal braces and brackets. Unknown placeholders
+// are left as-is.
+func Format(u UserAgent, tmpl string) string {
+	type section struct {
+		start int // Position of the "[" in b.
+		empty bool
+	}
+	var (
+		b     = make([]byte, 0, len(tmpl)+32)
+		stack []section
+	)
+	for i := 0; i < len(tmpl); i++ {
+		c := tmpl[i]
+		if (c == '{' || c == '}' || c == '[' || c == ']') && i+1 < len(tmpl) && tmpl[i+1] == c {
+			b = append(b, c)
+			i++
+			continue
+		}
+
+		switch c {
+		case '[':
+			// Written as-is, and removed once we find the matching "]".
+			stack = append(stack, section{start: len(b)})
+		case ']':
+			if len(stack) == 0 {
+				break
+			}
+			s := stack[len(stack)-1]
+			stack = stack[:len(stack)-1]
+			if s.empty {
+				b = b[:s.start]
+			} else {
+				b = append(b[:s.start], b[s.start+1:]...)
+			}
+			continue
+		case '{':
+			end := strings.IndexByte(tmpl[i:], '}')
+			if end == -1 {
+				break
+			}
+			v, ok := u.placeholder(tmpl[i+1 : i+end])
+			if !ok {
+				break
+			}
+			if v == "" && len(stack) > 0 {
+				stack[len(stack)-1].empty = true
+			}
+			b = append(b, v...)
+			i += end
+			continue
+		}
+		b = append(b, c)
+	}
+	return string(b)
+}
+
+func (u UserAgent) placeholder(name string) (string, bool) {
+	switch name {
+	case "browser":
+		return u.Browser(), true
+	case "browser.name":
+		return u.BrowserName, true
+	case "browser.version":
+		return u.BrowserVersion, true
+	case "browser.major":
+		return major(u.BrowserVersion, u.BrowserVer()), true
+	case "os":
+		return u.OS(), true
+	case "os.name":
+		return u.OSName, true
+	case "os.version":
+		return u.OSVersion, true
+	case "os.major":
+		return major(u.OSVersion, u.OSVer()), true
+	case "model":
+		return u.Model, true
+	case "app":
+		return u.App, true
+	}
+	return "", false
+}
+
+func major(s string, v Version) string {
+	switch {
+	case s == "":
+		return ""
+	case v.Name != "":
+		return v.Name
+	default:
+		return strconv.Itoa(v.Major)
+	}
+}
+
+// Format implements fmt.Formatter. The verbs are:
+//
+//	%s, %v    Same as String(): "Firefox 73 on Windows 10".
+//	%+s, %+v  Long form; also includes the model and app if set:
+//	          "Chrome 118 on Android 13 (Pixel 7, com.example.app)".
+//	%n        Short form, without versions: "Firefox on Windows".
+//	%b        Browser(): "Firefox 73".
+//	%o        OS(): "Windows 10".
+//	%q        Quoted String().
+//	%#v       Go syntax.
+//
+// The width and the - flag can be used for padding.
+func (u UserAgent) Format(f fmt.State, verb rune) {
+	var s string
+	switch verb {
+	case 's', 'v', 'q':
+		if verb == 'v' && f.Flag('#') {
+			type plain UserAgent
+			s = strings.Replace(fmt.Sprintf("%#v", plain(u)), "gadget.plain", "gadget.UserAgent", 1)
+			break
+		}
+		s = u.String()
+		if f.Flag('+') {
+			var extra []string
+			if u.Model != "" {
+				extra = append(extra, u.Model)
+			}
+			if u.App != "" {
+				extra = append(extra, u.App)
+			}
+			if len(extra) > 0 {
+				s = strings.TrimLeft(s+" ("+strings.Join(extra, ", ")+")", " ")
+			}
+		}
+		if verb == 'q' {
+			s = strconv.Quote(s)
+		}
+	case 'n':
+		s = UserAgent{BrowserName: u.BrowserName, OSName: u.OSName}.String()
+	case 'b':
+		s = u.Browser()
+	case 'o':
+		s = u.OS()
+	default:
+		fmt.Fprintf(f, "%%!%c(gadget.UserAgent=%s)", verb, u.String())
+		return
+	}
+
+	if w, ok := f.Width(); ok {
+		if n := utf8.RuneCountInString(s); n < w {
+			pad := strings.Repeat(" ", w-n)
+			if f.Flag('-') {
+				s += pad
+			} else {
+				s = pad + s
+			}
+		}
+	}
+	fmt.Fprint(f, s)
+}
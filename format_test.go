@@ -0,0 +1,106 @@
+package gadget
+
+import (
+	"fmt"
+	"testing"
+)
+
+func TestFormat(t *testing.T) {
+	var (
+		ff      = UserAgent{BrowserName: "Firefox", BrowserVersion: "73.1", OSName: "Windows", OSVersion: "XP"}
+		mac     = UserAgent{BrowserName: "Safari", BrowserVersion: "13.1", OSName: "macOS", OSVersion: "10.15"}
+		browser = UserAgent{BrowserName: "Firefox", BrowserVersion: "73"}
+	)
+
+	tests := []struct {
+		in   UserAgent
+		tmpl string
+		want string
+	}{
+		{ff, "", ""},
+		{ff, "{browser} — {os}", "Firefox 73.1 — Windows XP"},
+		{ff, "{browser.name} {browser.major} / {os.name} {os.major}", "Firefox 73 / Windows XP"},
+		{mac, "{browser.name} {browser.version} / {os.name} {os.major}", "Safari 13.1 / macOS 10"},
+		{mac, "{browser}[ sur {os}]", "Safari 13.1 sur macOS 10.15"},
+		{browser, "{browser}[ sur {os}]", "Firefox 73"},
+		{browser, "{browser}[ auf {os}", "Firefox 73[ auf "},
+		{browser, "[{os}] {browser}]", " Firefox 73]"},
+		{ff, "[{os}] {browser}]", "Windows XP Firefox 73.1]"},
+		{browser, "{browser}] [", "Firefox 73] ["},
+		{browser, "{browser}[ ({model}[, {app}])]", "Firefox 73"},
+		{UserAgent{BrowserName: "F", Model: "Pixel 7"}, "{browser}[ ({model}[, {app}])]", "F (Pixel 7)"},
+		{UserAgent{BrowserName: "F", Model: "Pixel 7", App: "x"}, "{browser}[ ({model}[, {app}])]", "F (Pixel 7, x)"},
+		{UserAgent{BrowserName: "F", App: "x"}, "{browser}[ ({model}[, {app}])]", "F"},
+		{UserAgent{BrowserName: "F", App: "x"}, "[[{browser}]][ [[{app}]]]", "[F] [x]"},
+		{browser, "{{browser}} [[{browser}]] {unknown} {browser", "{browser} [Firefox 73] {unknown} {browser"},
+		{UserAgent{Model: "Pixel 7", App: "com.example"}, "{model}/{app}", "Pixel 7/com.example"},
+	}
+
+	for i, tt := range tests {
+		t.Run(fmt.Sprintf("%d", i), func(t *testing.T) {
+			got := Format(tt.in, tt.tmpl)
+			if got != tt.want {
+				t.Errorf("\ngot:  %q\nwant: %q", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestJoin(t *testing.T) {
+	tests := []struct {
+		in   UserAgent
+		want string
+	}{
+		{UserAgent{}, ""},
+		{UserAgent{OSName: "x"}, "x"},
+		{UserAgent{BrowserName: "x"}, "x"},
+		{UserAgent{BrowserName: "x", OSName: "y"}, "x sur y"},
+	}
+
+	for i, tt := range tests {
+		t.Run(fmt.Sprintf("%d", i), func(t *testing.T) {
+			got := tt.in.Join("sur")
+			if got != tt.want {
+				t.Errorf("\ngot:  %q\nwant: %q", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestFormatter(t *testing.T) {
+	var (
+		ua     = UserAgent{BrowserName: "Chrome", BrowserVersion: "118", OSName: "Android", OSVersion: "13"}
+		detail = UserAgent{BrowserName: "Chrome", BrowserVersion: "118", OSName: "Android", OSVersion: "13", Model: "Pixel 7", App: "com.example.app"}
+	)
+
+	tests := []struct {
+		format string
+		in     UserAgent
+		want   string
+	}{
+		{"%s", ua, "Chrome 118 on Android 13"},
+		{"%v", ua, "Chrome 118 on Android 13"},
+		{"%+v", ua, "Chrome 118 on Android 13"},
+		{"%+v", detail, "Chrome 118 on Android 13 (Pixel 7, com.example.app)"},
+		{"%+s", UserAgent{Model: "Pixel 7"}, "(Pixel 7)"},
+		{"%n", ua, "Chrome on Android"},
+		{"%b", ua, "Chrome 118"},
+		{"%o", ua, "Android 13"},
+		{"%q", ua, `"Chrome 118 on Android 13"`},
+		{"%12b|", ua, "  Chrome 118|"},
+		{"%-12b|", ua, "Chrome 118  |"},
+		{"%-12b|", UserAgent{BrowserName: "Ñandú", BrowserVersion: "1"}, "Ñandú 1     |"},
+		{"%8b|", UserAgent{BrowserName: "日本"}, "      日本|"},
+		{"%#v", UserAgent{BrowserName: "x"}, `gadget.UserAgent{BrowserName:"x", BrowserVersion:"", OSName:"", OSVersion:"", Reduced:false, OSFromHints:false, Duplicate:false, Model:"", App:""}`},
+		{"%d", ua, "%!d(gadget.UserAgent=Chrome 118 on Android 13)"},
+	}
+
+	for i, tt := range tests {
+		t.Run(fmt.Sprintf("%d", i), func(t *testing.T) {
+			got := fmt.Sprintf(tt.format, tt.in)
+			if got != tt.want {
+				t.Errorf("\ngot:  %s\nwant: %s", got, tt.want)
+			}
+		})
+	}
+}
@@ -1,9 +1,86 @@
 package gadget
 
-import "net/http"
+import (
+	"net/http"
+	"strings"
+)
+
+// Headers with the original device User-Agent, as sent by Opera Mini and some
+// proxies and transcoders.
+var stockUAHeaders = []string{
+	"X-Operamini-Phone-Ua",
+	"Device-Stock-Ua",
+	"X-Device-User-Agent",
+}
 
 // Parse attempts to retrieve the browser and system name from the set of
 // headers.
+//
+// If there are multiple User-Agent headers the most specific one is used and
+// Duplicate is set. If there is no User-Agent header the browser and OS are
+// taken from the Sec-CH-UA* Client Hints. The Client Hints are also used to
+// get the actual OS version for reduced User-Agents.
+//
+// The OS is taken from the original device User-Agent if it's sent in one of
+// the X-OperaMini-Phone-UA, Device-Stock-UA, or X-Device-User-Agent headers,
+// and App is set from X-Requested-With for Android WebViews.
 func Parse(h http.Header) UserAgent {
-	return ParseUA(h.Get("User-Agent"))
+	var ua UserAgent
+	switch uaHeaders := h["User-Agent"]; len(uaHeaders) {
+	case 0:
+	case 1:
+		ua = ParseUA(uaHeaders[0])
+	default:
+		ua = mostSpecific(uaHeaders)
+		ua.Duplicate = true
+	}
+
+	hints := parseHints(h)
+	ua.Model = hints.Model
+	switch {
+	case ua.BrowserName == "" && ua.OSName == "" && h.Get("User-Agent") == "":
+		ua.BrowserName, ua.BrowserVersion = hints.BrowserName, hints.BrowserVersion
+		ua.OSName, ua.OSVersion, ua.OSFromHints = hints.OSName, hints.OSVersion, hints.OSFromHints
+	case hints.OSVersion != "" && (ua.Reduced || hints.OSName == ua.OSName):
+		ua.OSName, ua.OSVersion, ua.OSFromHints = hints.OSName, hints.OSVersion, true
+		ua.Reduced = false
+	}
+
+	for _, k := range stockUAHeaders {
+		stock := h.Get(k)
+		if stock == "" {
+			continue
+		}
+		if s := ParseUA(stock); s.OSName != "" {
+			ua.OSName, ua.OSVersion, ua.OSFromHints = s.OSName, s.OSVersion, false
+			break
+		}
+	}
+
+	// Also sent as "XMLHttpRequest" by many JavaScript libraries.
+	if app := h.Get("X-Requested-With"); app != "" && !strings.EqualFold(app, "XMLHttpRequest") {
+		ua.App = app
+	}
+	return ua
+}
+
+// Parse all User-Agent headers and get the one with the most information.
+func mostSpecific(uaHeaders []string) UserAgent {
+	var (
+		best      UserAgent
+		bestScore = -1
+	)
+	for _, h := range uaHeaders {
+		ua := ParseUA(h)
+		score := 0
+		for _, f := range []string{ua.BrowserName, ua.BrowserVersion, ua.OSName, ua.OSVersion} {
+			if f != "" {
+				score++
+			}
+		}
+		if score > bestScore {
+			best, bestScore = ua, score
+		}
+	}
+	return best
 }
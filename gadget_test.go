@@ -0,0 +1,97 @@
+package gadget
+
+import (
+	"fmt"
+	"net/http"
+	"testing"
+)
+
+func TestParse(t *testing.T) {
+	tests := []struct {
+		in   http.Header
+		want UserAgent
+	}{
+		{http.Header{}, UserAgent{}},
+		{
+			http.Header{"User-Agent": {"Mozilla/5.0 (Windows NT 10.0; Win64; x64; rv:73.0) Gecko/20100101 Firefox/73.0"}},
+			UserAgent{BrowserName: "Firefox", BrowserVersion: "73", OSName: "Windows", OSVersion: "10"},
+		},
+		{
+			http.Header{
+				"User-Agent":           {"Opera/9.80 (J2ME/MIDP; Opera Mini/9.80 (S60; SymbOS; Opera Mobi/23.348; U; en) Presto/2.5.25 Version/10.54"},
+				"X-Operamini-Phone-Ua": {"Mozilla/5.0 (Linux; Android 8.0.0; SM-G960F Build/R16NW) AppleWebKit/537.36 (KHTML, like Gecko) Chrome/62.0.3202.84 Mobile Safari/537.36"},
+			},
+			UserAgent{BrowserName: "Opera Mini", BrowserVersion: "9.80", OSName: "Android", OSVersion: "8"},
+		},
+		{
+			http.Header{
+				"User-Agent":      {"Mozilla/5.0 (Windows NT 10.0; Win64; x64; rv:73.0) Gecko/20100101 Firefox/73.0"},
+				"Device-Stock-Ua": {"junk"},
+			},
+			UserAgent{BrowserName: "Firefox", BrowserVersion: "73", OSName: "Windows", OSVersion: "10"},
+		},
+		{
+			http.Header{
+				"User-Agent":       {"Mozilla/5.0 (Linux; Android 10; SM-A205U Build/QP1A.190711.020; wv) AppleWebKit/537.36 (KHTML, like Gecko) Version/4.0 Chrome/81.0.4044.117 Mobile Safari/537.36"},
+				"X-Requested-With": {"com.example.app"},
+			},
+			UserAgent{BrowserName: "Chrome", BrowserVersion: "81", OSName: "Android", OSVersion: "10", App: "com.example.app"},
+		},
+		{
+			http.Header{
+				"User-Agent":       {"Mozilla/5.0 (Windows NT 10.0; Win64; x64; rv:73.0) Gecko/20100101 Firefox/73.0"},
+				"X-Requested-With": {"XMLHttpRequest"},
+			},
+			UserAgent{BrowserName: "Firefox", BrowserVersion: "73", OSName: "Windows", OSVersion: "10"},
+		},
+
+		// Duplicate headers.
+		{
+			http.Header{"User-Agent": {"curl/7.0", "Mozilla/5.0 (Windows NT 10.0; Win64; x64; rv:73.0) Gecko/20100101 Firefox/73.0"}},
+			UserAgent{BrowserName: "Firefox", BrowserVersion: "73", OSName: "Windows", OSVersion: "10", Duplicate: true},
+		},
+
+		// Client Hints.
+		{
+			http.Header{
+				"Sec-Ch-Ua":          {`"Chromium";v="118", "Google Chrome";v="118", "Not=A?Brand";v="99"`},
+				"Sec-Ch-Ua-Platform": {`"Android"`},
+			},
+			UserAgent{BrowserName: "Chrome", BrowserVersion: "118", OSName: "Android"},
+		},
+		{
+			http.Header{
+				"User-Agent":                 {"Mozilla/5.0 (Linux; Android 10; K) AppleWebKit/537.36 (KHTML, like Gecko) Chrome/118.0.0.0 Mobile Safari/537.36"},
+				"Sec-Ch-Ua":                  {`"Chromium";v="118", "Google Chrome";v="118", "Not=A?Brand";v="99"`},
+				"Sec-Ch-Ua-Platform":         {`"Android"`},
+				"Sec-Ch-Ua-Platform-Version": {`"13.0.0"`},
+				"Sec-Ch-Ua-Model":            {`"Pixel 7"`},
+			},
+			UserAgent{BrowserName: "Chrome", BrowserVersion: "118", OSName: "Android", OSVersion: "13", Model: "Pixel 7", OSFromHints: true},
+		},
+		{
+			http.Header{
+				"User-Agent":         {"Mozilla/5.0 (Linux; Android 10; K) AppleWebKit/537.36 (KHTML, like Gecko) Chrome/118.0.0.0 Mobile Safari/537.36"},
+				"Sec-Ch-Ua-Platform": {`"Android"`},
+			},
+			UserAgent{BrowserName: "Chrome", BrowserVersion: "118", OSName: "Android", OSVersion: "10", Reduced: true},
+		},
+		{
+			http.Header{
+				"User-Agent":                 {"Mozilla/5.0 (Windows NT 10.0; Win64; x64) AppleWebKit/537.36 (KHTML, like Gecko) Chrome/99.0.4844.51 Safari/537.36"},
+				"Sec-Ch-Ua-Platform":         {`"Windows"`},
+				"Sec-Ch-Ua-Platform-Version": {`"14.0.0"`},
+			},
+			UserAgent{BrowserName: "Chrome", BrowserVersion: "99", OSName: "Windows", OSVersion: "11", OSFromHints: true},
+		},
+	}
+
+	for i, tt := range tests {
+		t.Run(fmt.Sprintf("%d", i), func(t *testing.T) {
+			got := Parse(tt.in)
+			if got != tt.want {
+				t.Errorf("\ngot:  %#v\nwant: %#v", got, tt.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,257 @@
+//go:build ignore
+// +build ignore
+
+// Command gen_features generates features_data.go from MDN's
+// browser-compat-data and caniuse.
+//
+// Usage:
+//
+//	go run gen_features.go -bcd path/to/bcd/data.json -caniuse path/to/caniuse/data.json
+//
+// The data.json for browser-compat-data is in the @mdn/browser-compat-data npm
+// package, and the one for caniuse is in the caniuse-db npm package; the
+// defaults are the paths after "npm install" in the current directory, which
+// is what "go generate" uses.
+package main
+
+import (
+	"bytes"
+	"encoding/json"
+	"flag"
+	"fmt"
+	"go/format"
+	"io/ioutil"
+	"os"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+// Features to include, and where to find them: "bcd:" is a dotted path in
+// browser-compat-data, and "caniuse:" is a caniuse feature ID.
+var features = map[string]string{
+	"arrow-functions":       "bcd:javascript.functions.arrow_functions",
+	"async-functions":       "bcd:javascript.statements.async_function",
+	"avif":                  "caniuse:avif",
+	"css-custom-properties": "bcd:css.properties.custom-property",
+	"css-grid":              "bcd:css.properties.grid",
+	"css-has":               "bcd:css.selectors.has",
+	"dialog":                "bcd:html.elements.dialog",
+	"es-classes":            "bcd:javascript.classes",
+	"es-modules":            "bcd:javascript.statements.import",
+	"fetch":                 "bcd:api.fetch",
+	"intersection-observer": "bcd:api.IntersectionObserver",
+	"nullish-coalescing":    "bcd:javascript.operators.nullish_coalescing",
+	"optional-chaining":     "bcd:javascript.operators.optional_chaining",
+	"promises":              "bcd:javascript.builtins.Promise",
+	"service-workers":       "bcd:api.ServiceWorker",
+	"webassembly":           "bcd:webassembly",
+	"webp":                  "caniuse:webp",
+}
+
+// Browser names in browser-compat-data and caniuse, mapped to the BrowserName
+// gadget uses. Safari on iOS is "iOS Safari", as it's versioned with iOS and
+// supports different features than Safari on macOS.
+var browsers = []struct{ bcd, caniuse, name string }{
+	{"chrome", "chrome", "Chrome"},
+	{"edge", "edge", "Edge"},
+	{"firefox", "firefox", "Firefox"},
+	{"ie", "ie", "Internet Explorer"},
+	{"opera", "opera", "Opera"},
+	{"safari", "safari", "Safari"},
+	{"safari_ios", "ios_saf", "iOS Safari"},
+}
+
+// The Chromium-based Edge and Opera are reported as Chrome; only include the
+// EdgeHTML and Presto versions.
+var maxVersion = map[string]float64{
+	"Edge":  18,
+	"Opera": 12.16,
+}
+
+func main() {
+	var (
+		bcdFile     = flag.String("bcd", "node_modules/@mdn/browser-compat-data/data.json", "path to browser-compat-data data.json")
+		caniuseFile = flag.String("caniuse", "node_modules/caniuse-db/data.json", "path to caniuse data.json")
+		out         = flag.String("o", "features_data.go", "output file")
+	)
+	flag.Parse()
+
+	var bcd, caniuse map[string]interface{}
+	if err := readJSON(*bcdFile, &bcd); err != nil {
+		fatal(err)
+	}
+	if err := readJSON(*caniuseFile, &caniuse); err != nil {
+		fatal(err)
+	}
+
+	data := make(map[string]map[string]string)
+	for id, src := range features {
+		var (
+			min map[string]string
+			err error
+		)
+		switch {
+		case strings.HasPrefix(src, "bcd:"):
+			min, err = fromBCD(bcd, src[4:])
+		case strings.HasPrefix(src, "caniuse:"):
+			min, err = fromCaniuse(caniuse, src[8:])
+		default:
+			err = fmt.Errorf("unknown source %q", src)
+		}
+		if err != nil {
+			fatal(fmt.Errorf("%s: %w", id, err))
+		}
+		data[id] = min
+	}
+
+	src, err := format.Source(render(data))
+	if err != nil {
+		fatal(err)
+	}
+	if err := ioutil.WriteFile(*out, src, 0644); err != nil {
+		fatal(err)
+	}
+}
+
+func readJSON(path string, v interface{}) error {
+	if path == "" {
+		return fmt.Errorf("need both -bcd and -caniuse")
+	}
+	d, err := ioutil.ReadFile(path)
+	if err != nil {
+		return err
+	}
+	return json.Unmarshal(d, v)
+}
+
+// Get the first version without flags or prefixes from the "support" entry.
+func fromBCD(bcd map[string]interface{}, path string) (map[string]string, error) {
+	var node interface{} = bcd
+	for _, p := range strings.Split(path, ".") {
+		m, ok := node.(map[string]interface{})
+		if !ok {
+			return nil, fmt.Errorf("no such path: %q", path)
+		}
+		node = m[p]
+	}
+	m, _ := node.(map[string]interface{})
+	compat, _ := m["__compat"].(map[string]interface{})
+	support, _ := compat["support"].(map[string]interface{})
+	if support == nil {
+		return nil, fmt.Errorf("no support data at %q", path)
+	}
+
+	min := make(map[string]string)
+	for _, b := range browsers {
+		var stmts []interface{}
+		switch s := support[b.bcd].(type) {
+		case []interface{}:
+			stmts = s
+		case map[string]interface{}:
+			stmts = []interface{}{s}
+		}
+		for _, st := range stmts {
+			st, _ := st.(map[string]interface{})
+			if st["flags"] != nil || st["prefix"] != nil || st["alternative_name"] != nil ||
+				st["partial_implementation"] == true || st["version_removed"] != nil {
+				continue
+			}
+			v, _ := st["version_added"].(string)
+			if v = strings.TrimLeft(v, "≤"); include(b.name, v) {
+				min[b.name] = v
+				break
+			}
+		}
+	}
+	return min, nil
+}
+
+// Get the lowest version with full ("y") support.
+func fromCaniuse(caniuse map[string]interface{}, id string) (map[string]string, error) {
+	data, _ := caniuse["data"].(map[string]interface{})
+	feat, _ := data[id].(map[string]interface{})
+	stats, _ := feat["stats"].(map[string]interface{})
+	if stats == nil {
+		return nil, fmt.Errorf("no such caniuse feature: %q", id)
+	}
+
+	min := make(map[string]string)
+	for _, b := range browsers {
+		versions, _ := stats[b.caniuse].(map[string]interface{})
+		var lowest string
+		for v, s := range versions {
+			v = strings.Split(v, "-")[0] // "15.2-15.3"
+			if s, _ := s.(string); !strings.HasPrefix(s, "y") || !include(b.name, v) {
+				continue
+			}
+			if lowest == "" || parseFloat(v) < parseFloat(lowest) {
+				lowest = v
+			}
+		}
+		if lowest != "" {
+			min[b.name] = lowest
+		}
+	}
+	return min, nil
+}
+
+func include(name, v string) bool {
+	f := parseFloat(v)
+	if f == 0 {
+		return false
+	}
+	max, ok := maxVersion[name]
+	return !ok || f <= max
+}
+
+// Good enough for comparing browser versions such as "10.1" and "16.4".
+func parseFloat(v string) float64 {
+	f, _ := strconv.ParseFloat(v, 64)
+	return f
+}
+
+func render(data map[string]map[string]string) []byte {
+	b := new(bytes.Buffer)
+	b.WriteString(`// Code generated by gen_features.go; DO NOT EDIT.
+
+package gadget
+
+// Minimum browser versions for web features, keyed by the BrowserName
+// ParseUA() uses, or "iOS Safari" for Safari on iOS. Browsers that never
+// supported a feature are omitted.
+//
+// This is a snapshot of MDN's browser-compat-data, with the image formats from
+// caniuse; update with "go generate".
+var features = map[string]map[string]string{
+`)
+
+	ids := make([]string, 0, len(data))
+	for id := range data {
+		ids = append(ids, id)
+	}
+	sort.Strings(ids)
+	for _, id := range ids {
+		names := make([]string, 0, len(data[id]))
+		for n := range data[id] {
+			names = append(names, n)
+		}
+		sort.Strings(names)
+
+		fmt.Fprintf(b, "\t%q: {", id)
+		for i, n := range names {
+			if i > 0 {
+				b.WriteString(", ")
+			}
+			fmt.Fprintf(b, "%q: %q", n, data[id][n])
+		}
+		b.WriteString("},\n")
+	}
+	b.WriteString("}\n")
+	return b.Bytes()
+}
+
+func fatal(err error) {
+	fmt.Fprintln(os.Stderr, "gen_features:", err)
+	os.Exit(1)
+}
@@ -0,0 +1,403 @@
+//go:build ignore
+// +build ignore
+
+// Command gen_shortdict generates a new version of the ShortenUA() dictionary
+// from a corpus of User-Agent strings.
+//
+// Usage:
+//
+//	go run gen_shortdict.go -version 3 -eval testdata/testcases_ua_parser_js testdata/top500 ...
+//
+// This writes short_v3.go with the shortTokensV3 variable, which needs to be
+// added to shortDicts in short.go. Existing versions are never overwritten, as
+// that would change how stored strings are decoded.
+//
+// The input files have one User-Agent per line; blank lines and lines starting
+// with # are skipped. For tab-separated lines only the last column is used,
+// which may be shortened with any version of ShortenUA().
+//
+// The dictionary always contains the strings in required, and is then filled
+// by repeatedly adding the string that saves the most bytes when encoding the
+// corpus. The savings are reported for the -eval files, which aren't used to
+// build the dictionary.
+//
+// To keep the output readable only strings of whole words are considered, and
+// they never include a number (e.g. "537.36" in "AppleWebKit/537.36" or "10.0"
+// in "Windows NT 10.0"), except for the ubiquitous "Mozilla/5.0". They also
+// don't start or end with a space or punctuation other than a "/" or ":" at the
+// end, and brackets must be balanced: "Win64; x64" is fine, but "x64) " is not.
+// Device models (e.g. "SM-G950F") and locales (e.g. "en" or "en-US") are
+// skipped as well: they may be common in the corpus, but are specific to a few
+// devices or users.
+// Tokens are "~" followed by a letter, and use a letter from the string if
+// possible.
+package main
+
+import (
+	"bufio"
+	"bytes"
+	"flag"
+	"fmt"
+	"go/format"
+	"io/ioutil"
+	"os"
+	"sort"
+	"strings"
+
+	"zgo.at/gadget"
+)
+
+// Letters that can be used for tokens.
+const letters = "abcdefghijklmnopqrstuvwxyzABCDEFGHIJKLMNOPQRSTUVWXYZ"
+
+// Strings that are always in the dictionary, even if they're not common in the
+// corpus: frequent in real traffic, but the corpus may lag behind.
+var required = []string{
+	"Win64; x64", "Intel Mac OS X", "like Mac OS X", "X11", "rv:",
+	"Edg/", "OPR/", "SamsungBrowser/", "CriOS/",
+}
+
+// Separators between words.
+const seps = " ();,/:"
+
+func main() {
+	var (
+		version   = flag.Int("version", 0, "dictionary version to generate")
+		maxTokens = flag.Int("n", len(letters), "maximum number of tokens")
+		minCount  = flag.Int("min", 10, "minimum number of times a string needs to occur")
+		maxWords  = flag.Int("words", 8, "maximum number of words in a string")
+		eval      = flag.String("eval", "", "comma-separated list of files to report the savings for")
+	)
+	flag.Parse()
+	if *version < 2 || *version > 9 {
+		fatal(fmt.Errorf("-version must be between 2 and 9; see the shortDicts comment in short.go for more versions"))
+	}
+	if flag.NArg() == 0 {
+		fatal(fmt.Errorf("need at least one input file"))
+	}
+	out := fmt.Sprintf("short_v%d.go", *version)
+	if _, err := os.Stat(out); err == nil {
+		fatal(fmt.Errorf("%s already exists; never change an existing version", out))
+	}
+	if *maxTokens > len(letters) {
+		*maxTokens = len(letters)
+	}
+
+	var all []string
+	for _, f := range flag.Args() {
+		uas, err := readCorpus(f)
+		if err != nil {
+			fatal(err)
+		}
+		all = append(all, uas...)
+	}
+
+	var (
+		chosen = append([]string{}, required...)
+		size   = encodedSize(all, chosen)
+	)
+	for len(chosen) < *maxTokens {
+		// Get the best candidates from what's left after encoding with the
+		// current dictionary, and then try them with the actual encoder as
+		// earlier tokens may take precedence.
+		enc, _ := replacers(assignTokens(chosen))
+		work := make([]string, len(all))
+		for i := range all {
+			work[i] = enc.Replace(all[i])
+		}
+
+		var (
+			best    string
+			bestLen = size
+		)
+		for _, c := range candidates(work, *minCount, *maxWords, 20) {
+			if n := encodedSize(all, append(chosen, c)); n < bestLen {
+				best, bestLen = c, n
+			}
+		}
+		if best == "" {
+			break
+		}
+		fmt.Fprintf(os.Stderr, "%-40q saves %d bytes\n", best, size-bestLen)
+		chosen, size = append(chosen, best), bestLen
+	}
+
+	tokens := assignTokens(chosen)
+	enc, dec := replacers(tokens)
+	for _, ua := range all {
+		if got := dec.Replace(enc.Replace(ua)); got != ua {
+			fatal(fmt.Errorf("doesn't round-trip:\n%q\n%q", ua, got))
+		}
+	}
+
+	src, err := format.Source(render(*version, flag.Args(), tokens))
+	if err != nil {
+		fatal(err)
+	}
+	if err := ioutil.WriteFile(out, src, 0644); err != nil {
+		fatal(err)
+	}
+
+	if *eval == "" {
+		return
+	}
+	fmt.Printf("%-40s %9s %9s %9s\n", "file", "bytes", "v1", fmt.Sprintf("v%d", *version))
+	var total [3]int
+	for _, f := range strings.Split(*eval, ",") {
+		uas, err := readCorpus(f)
+		if err != nil {
+			fatal(err)
+		}
+		var size [3]int
+		for _, ua := range uas {
+			v1, _ := gadget.ShortenUAVersion(ua, 1)
+			size[0] += len(ua)
+			size[1] += len(v1)
+			size[2] += len(enc.Replace(ua)) + 2 // +2 for the version marker.
+		}
+		printSize(f, size)
+		for i := range total {
+			total[i] += size[i]
+		}
+	}
+	printSize("total", total)
+}
+
+func printSize(name string, size [3]int) {
+	pct := func(n int) string { return fmt.Sprintf("%.1f%%", 100-float64(n)/float64(size[0])*100) }
+	fmt.Printf("%-40s %9d %9s %9s\n", name, size[0], pct(size[1]), pct(size[2]))
+}
+
+func readCorpus(path string) ([]string, error) {
+	fp, err := os.Open(path)
+	if err != nil {
+		return nil, err
+	}
+	defer fp.Close()
+
+	var uas []string
+	scanner := bufio.NewScanner(fp)
+	for scanner.Scan() {
+		line := scanner.Text()
+		if line == "" || line[0] == '#' {
+			continue
+		}
+		uas = append(uas, gadget.UnshortenUA(line[strings.LastIndexByte(line, '\t')+1:]))
+	}
+	return uas, scanner.Err()
+}
+
+// Split a User-Agent in to words; every word includes the separator it ends
+// with, if any.
+func words(ua string) []string {
+	var w []string
+	for {
+		i := strings.IndexAny(ua, seps)
+		if i == -1 {
+			if ua != "" {
+				w = append(w, ua)
+			}
+			return w
+		}
+		w = append(w, ua[:i+1])
+		ua = ua[i+1:]
+	}
+}
+
+// Get the n candidates that would save the most bytes if there was no overlap
+// with other tokens.
+func candidates(work []string, minCount, maxWords, n int) []string {
+	count := make(map[string]int)
+	for _, ua := range work {
+		w := words(ua)
+		for i := range w {
+			for j := i + 1; j <= len(w) && j <= i+maxWords; j++ {
+				if !allowed(w, j-1) {
+					break
+				}
+				if c := trim(strings.Join(w[i:j], "")); readable(c) {
+					count[c]++
+				}
+			}
+		}
+	}
+
+	var cand []string
+	for c, n := range count {
+		if n >= minCount && len(c) >= 4 {
+			cand = append(cand, c)
+		}
+	}
+	saves := func(c string) int { return count[c] * (len(c) - 2) }
+	sort.Slice(cand, func(i, j int) bool {
+		if saves(cand[i]) != saves(cand[j]) {
+			return saves(cand[i]) > saves(cand[j])
+		}
+		return cand[i] < cand[j]
+	})
+	if len(cand) > n {
+		cand = cand[:n]
+	}
+	return cand
+}
+
+// Get the total size of the corpus after encoding with these strings.
+func encodedSize(all, chosen []string) int {
+	enc, _ := replacers(assignTokens(chosen))
+	n := 0
+	for _, ua := range all {
+		n += len(enc.Replace(ua))
+	}
+	return n
+}
+
+// Report if a word can be used in a token: it can't contain a "~" or start
+// with a number, except for the "5.0" in "Mozilla/5.0".
+func allowed(w []string, i int) bool {
+	if strings.Contains(w[i], "~") {
+		return false
+	}
+	if w[i][0] >= '0' && w[i][0] <= '9' {
+		return i > 0 && w[i-1] == "Mozilla/" && strings.TrimRight(w[i], seps) == "5.0"
+	}
+	return true
+}
+
+// Remove trailing separators, except a "/" or ":" that ends a product name or
+// key (e.g. "Chrome/" or "rv:").
+func trim(c string) string {
+	for c != "" {
+		switch c[len(c)-1] {
+		case ' ', ';', ',', '(':
+			c = c[:len(c)-1]
+		case ')':
+			if strings.Count(c, "(") >= strings.Count(c, ")") {
+				return c
+			}
+			c = c[:len(c)-1]
+		default:
+			return c
+		}
+	}
+	return c
+}
+
+// Report if c is readable as a token: it starts with a letter or a "(", all
+// brackets are balanced, and it has no device models or locales.
+func readable(c string) bool {
+	if c == "" || (c[0] != '(' && strings.IndexByte(letters, c[0]) == -1) {
+		return false
+	}
+	for _, w := range strings.FieldsFunc(c, func(r rune) bool { return strings.ContainsRune(" ();,", r) }) {
+		if isModel(w) || isLocale(w) {
+			return false
+		}
+	}
+	depth := 0
+	for i := 0; i < len(c); i++ {
+		switch c[i] {
+		case '(':
+			depth++
+		case ')':
+			depth--
+			if depth < 0 {
+				return false
+			}
+		}
+	}
+	return depth == 0
+}
+
+// Report if w looks like a device model: a dash and a number, as in "SM-G950F"
+// or "GT-I9300".
+func isModel(w string) bool {
+	return strings.IndexByte(w, '-') > -1 && strings.IndexAny(w, "0123456789") > -1
+}
+
+// Report if w looks like a locale: "en", "en-US", or "en_us".
+func isLocale(w string) bool {
+	lower := func(b byte) bool { return b >= 'a' && b <= 'z' }
+	alpha := func(b byte) bool { return lower(b) || (b >= 'A' && b <= 'Z') }
+	switch len(w) {
+	case 2:
+		return lower(w[0]) && lower(w[1])
+	case 5:
+		return lower(w[0]) && lower(w[1]) && (w[2] == '-' || w[2] == '_') && alpha(w[3]) && alpha(w[4])
+	}
+	return false
+}
+
+type token struct{ long, short string }
+
+// Assign a token to every string, and sort them so longer strings are tried
+// first.
+//
+// Strings are assigned in the order they were chosen, so the most common
+// strings get the most readable token: the letter version 1 used for mostly
+// the same string, or the first available letter of the string.
+func assignTokens(chosen []string) []token {
+	used := make(map[byte]bool)
+	tokens := make([]token, 0, len(chosen)+1)
+	for _, c := range chosen {
+		var try []byte
+		if v1, _ := gadget.ShortenUAVersion(c, 1); len(c)-len(v1)+2 >= len(c)/2 {
+			if i := strings.IndexByte(v1, '~'); i > -1 && i < len(v1)-1 {
+				try = append(try, v1[i+1])
+			}
+		}
+		for j := 0; j < len(c); j++ {
+			if strings.IndexByte(letters, c[j]) > -1 {
+				try = append(try, c[j], c[j]^0x20) // Both cases.
+			}
+		}
+		try = append(try, letters...)
+
+		for _, t := range try {
+			if strings.IndexByte(letters, t) > -1 && !used[t] {
+				used[t] = true
+				tokens = append(tokens, token{c, "~" + string(t)})
+				break
+			}
+		}
+	}
+
+	sort.Slice(tokens, func(i, j int) bool {
+		if len(tokens[i].long) != len(tokens[j].long) {
+			return len(tokens[i].long) > len(tokens[j].long)
+		}
+		return tokens[i].long < tokens[j].long
+	})
+	return append([]token{{"~", "~~"}}, tokens...)
+}
+
+func replacers(tokens []token) (*strings.Replacer, *strings.Replacer) {
+	var enc, dec []string
+	for _, t := range tokens {
+		enc = append(enc, t.long, t.short)
+		dec = append(dec, t.short, t.long)
+	}
+	return strings.NewReplacer(enc...), strings.NewReplacer(dec...)
+}
+
+func render(version int, files []string, tokens []token) []byte {
+	b := new(bytes.Buffer)
+	fmt.Fprintf(b, `// Code generated by gen_shortdict.go; DO NOT EDIT.
+
+package gadget
+
+// Version %[1]d of the ShortenUA() dictionary, generated from:
+//
+//	%[2]s
+var shortTokensV%[1]d = []shortToken{
+`, version, strings.Join(files, " "))
+	for _, t := range tokens {
+		fmt.Fprintf(b, "\t{%q, %q},\n", t.long, t.short)
+	}
+	b.WriteString("}\n")
+	return b.Bytes()
+}
+
+func fatal(err error) {
+	fmt.Fprintln(os.Stderr, "gen_shortdict:", err)
+	os.Exit(1)
+}
@@ -0,0 +1,228 @@
+package gadget
+
+import (
+	"encoding/json"
+	"net/http"
+	"strconv"
+	"strings"
+)
+
+// Brands based on Chromium; these are all reported as "Chrome", just like when
+// parsing the User-Agent header.
+var chromiumBrands = map[string]struct{}{
+	"Chromium":         {},
+	"Google Chrome":    {},
+	"Microsoft Edge":   {},
+	"Opera":            {},
+	"Opera GX":         {},
+	"Brave":            {},
+	"Vivaldi":          {},
+	"Samsung Internet": {},
+	"YaBrowser":        {},
+	"Yandex":           {},
+	"HeadlessChrome":   {},
+	"Android WebView":  {},
+}
+
+// brand is a single brand from Sec-CH-UA or navigator.userAgentData.brands.
+type brand struct{ name, version string }
+
+// UAData is the result of navigator.userAgentData.getHighEntropyValues() in
+// JavaScript.
+//
+// Architecture and Bitness are accepted but not used.
+type UAData struct {
+	Brands          []UADataBrand `json:"brands"`
+	FullVersionList []UADataBrand `json:"fullVersionList"`
+	Mobile          bool          `json:"mobile"`
+	Platform        string        `json:"platform"`
+	PlatformVersion string        `json:"platformVersion"`
+	Model           string        `json:"model"`
+	Architecture    string        `json:"architecture"`
+	Bitness         string        `json:"bitness"`
+}
+
+// UADataBrand is a single brand in UAData.
+type UADataBrand struct {
+	Brand   string `json:"brand"`
+	Version string `json:"version"`
+}
+
+// ParseUAData parses the JSON representation of UAData, as sent by a
+// JavaScript beacon.
+//
+// This uses the same rules as Parse() does for the Client Hints headers, so
+// both should give the same result.
+func ParseUAData(data []byte) (UserAgent, error) {
+	var d UAData
+	err := json.Unmarshal(data, &d)
+	if err != nil {
+		return UserAgent{}, err
+	}
+	return d.UserAgent(), nil
+}
+
+// UserAgent gets the browser and system from the UAData.
+func (d UAData) UserAgent() UserAgent {
+	list := d.FullVersionList
+	if len(list) == 0 {
+		list = d.Brands
+	}
+	brands := make([]brand, 0, len(list))
+	for _, b := range list {
+		brands = append(brands, brand{name: b.Brand, version: b.Version})
+	}
+	return fromHints(brands, d.Platform, d.PlatformVersion, d.Model)
+}
+
+// parseHints gets the browser and OS from the Sec-CH-UA* Client Hints headers.
+func parseHints(h http.Header) UserAgent {
+	brands := h.Get("Sec-Ch-Ua-Full-Version-List")
+	if brands == "" {
+		brands = h.Get("Sec-Ch-Ua")
+	}
+	return fromHints(parseBrands(brands),
+		unquote(h.Get("Sec-Ch-Ua-Platform")),
+		unquote(h.Get("Sec-Ch-Ua-Platform-Version")),
+		unquote(h.Get("Sec-Ch-Ua-Model")))
+}
+
+// fromHints creates a UserAgent from Client Hints values.
+func fromHints(brands []brand, platform, platformVersion, model string) UserAgent {
+	ua := UserAgent{Model: model}
+
+	// Prefer the Chromium brand, as that's always the engine version and the
+	// "Google Chrome" or "Microsoft Edge" ones may not match it.
+	for _, b := range brands {
+		if isGrease(b.name) {
+			continue
+		}
+		if _, ok := chromiumBrands[b.name]; ok {
+			if ua.BrowserName == "" || b.name == "Chromium" {
+				ua.BrowserName = "Chrome"
+				ua.BrowserVersion = maxVersion(b.version, 1, false)
+			}
+			continue
+		}
+		if ua.BrowserName == "" {
+			ua.BrowserName = b.name
+			ua.BrowserVersion = maxVersion(b.version, 2, false)
+		}
+	}
+
+	switch platform {
+	case "", "Unknown":
+	case "Windows":
+		ua.OSName = "Windows"
+		ua.OSVersion = windowsPlatformVersion(platformVersion)
+	case "macOS":
+		ua.OSName = "macOS"
+		ua.OSVersion = maxVersion(platformVersion, 2, false)
+	case "iOS":
+		ua.OSName = "iOS"
+		ua.OSVersion = maxVersion(platformVersion, 2, false)
+	case "Android":
+		ua.OSName = "Android"
+		ua.OSVersion = maxVersion(platformVersion, 2, true)
+	case "Chrome OS", "Chromium OS":
+		ua.OSName = "Chrome OS"
+	default:
+		ua.OSName = platform
+	}
+	ua.OSFromHints = ua.OSVersion != ""
+	return ua
+}
+
+// Map Sec-CH-UA-Platform-Version for Windows to the product version:
+// https://learn.microsoft.com/en-us/microsoft-edge/web-platform/how-to-detect-win11
+func windowsPlatformVersion(v string) string {
+	switch {
+	case v == "":
+		return ""
+	case strings.HasPrefix(v, "0.1."):
+		return "7"
+	case strings.HasPrefix(v, "0.2."):
+		return "8"
+	case strings.HasPrefix(v, "0.3."):
+		return "8.1"
+	}
+
+	i := strings.IndexByte(v, '.')
+	if i == -1 {
+		i = len(v)
+	}
+	major, err := strconv.Atoi(v[:i])
+	switch {
+	case err != nil || major < 1:
+		return ""
+	case major >= 13:
+		return "11"
+	default:
+		return "10"
+	}
+}
+
+// Parse a Sec-CH-UA header:
+//
+//	"Chromium";v="118", "Google Chrome";v="118", "Not=A?Brand";v="99"
+func parseBrands(h string) []brand {
+	var brands []brand
+	for _, item := range splitQuoted(h, ',') {
+		params := splitQuoted(item, ';')
+		b := brand{name: unquote(params[0])}
+		if b.name == "" {
+			continue
+		}
+		for _, p := range params[1:] {
+			p = strings.TrimSpace(p)
+			if strings.HasPrefix(p, "v=") {
+				b.version = unquote(p[2:])
+			}
+		}
+		brands = append(brands, b)
+	}
+	return brands
+}
+
+// Split s on sep, except when it's in a quoted string.
+func splitQuoted(s string, sep byte) []string {
+	var (
+		split  []string
+		quoted bool
+		start  int
+	)
+	for i := 0; i < len(s); i++ {
+		switch s[i] {
+		case '\\':
+			i++
+		case '"':
+			quoted = !quoted
+		case sep:
+			if !quoted {
+				split = append(split, s[start:i])
+				start = i + 1
+			}
+		}
+	}
+	return append(split, s[start:])
+}
+
+// GREASE brands are randomly generated names such as "Not=A?Brand" or " Not A;
+// Brand" to prevent sniffing on exact values:
+// https://wicg.github.io/ua-client-hints/#grease
+func isGrease(name string) bool {
+	name = strings.TrimSpace(name)
+	if !strings.HasPrefix(name, "Not") {
+		return false
+	}
+	return strings.ContainsAny(name, " ()-./:;=?_")
+}
+
+// Remove the quotes from a structured header string.
+func unquote(s string) string {
+	s = strings.TrimSpace(s)
+	if len(s) < 2 || s[0] != '"' || s[len(s)-1] != '"' {
+		return s
+	}
+	return strings.NewReplacer(`\"`, `"`, `\\`, `\`).Replace(s[1 : len(s)-1])
+}
@@ -0,0 +1,123 @@
+package gadget
+
+import (
+	"fmt"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func TestParseBrands(t *testing.T) {
+	tests := []struct {
+		in   string
+		want []brand
+	}{
+		{"", nil},
+		{`"Chromium";v="118", "Google Chrome";v="118", "Not=A?Brand";v="99"`,
+			[]brand{{"Chromium", "118"}, {"Google Chrome", "118"}, {"Not=A?Brand", "99"}}},
+		{`" Not A;Brand";v="99", "Chromium";v="96"`,
+			[]brand{{" Not A;Brand", "99"}, {"Chromium", "96"}}},
+		{`"Not)A;Brand";v="8.0.0.0", "Chromium";v="138.0.7204.101"`,
+			[]brand{{"Not)A;Brand", "8.0.0.0"}, {"Chromium", "138.0.7204.101"}}},
+		{`"Esc\"aped";v="1"`, []brand{{`Esc"aped`, "1"}}},
+	}
+
+	for i, tt := range tests {
+		t.Run(fmt.Sprintf("%d", i), func(t *testing.T) {
+			got := parseBrands(tt.in)
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("\ngot:  %#v\nwant: %#v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestFromHints(t *testing.T) {
+	tests := []struct {
+		brands                    string
+		platform, platformVersion string
+		want                      string
+	}{
+		{"", "", "", ""},
+		{`"Chromium";v="118", "Google Chrome";v="118", "Not=A?Brand";v="99"`, "Windows", "15.0.0", "Chrome 118 on Windows 11"},
+		{`"Not_A Brand";v="8", "Chromium";v="120", "Microsoft Edge";v="120"`, "Windows", "10.0.0", "Chrome 120 on Windows 10"},
+		{`"Microsoft Edge";v="109", "Not(A:Brand";v="24"`, "Windows", "0.1.0", "Chrome 109 on Windows 7"},
+		{`"Google Chrome";v="119.0.6045.105", "Chromium";v="119.0.6045.105"`, "Android", "13.0.0", "Chrome 119 on Android 13"},
+		{`"Google Chrome";v="119"`, "macOS", "14.1.0", "Chrome 119 on macOS 14.1"},
+		{`"Google Chrome";v="119"`, "Chrome OS", "14541.0.0", "Chrome 119 on Chrome OS"},
+		{`"Google Chrome";v="119"`, "Linux", "6.5.0", "Chrome 119 on Linux"},
+		{`"Google Chrome";v="119"`, "Unknown", "", "Chrome 119"},
+		{`"Not A(Brand";v="99", "SomeBrowser";v="5.1.2"`, "", "", "SomeBrowser 5.1"},
+	}
+
+	for i, tt := range tests {
+		t.Run(fmt.Sprintf("%d", i), func(t *testing.T) {
+			got := fromHints(parseBrands(tt.brands), tt.platform, tt.platformVersion, "").String()
+			if got != tt.want {
+				t.Errorf("\ngot:  %q\nwant: %q", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestParseUAData(t *testing.T) {
+	tests := []struct {
+		in      string
+		want    UserAgent
+		wantErr string
+	}{
+		{`{}`, UserAgent{}, ""},
+		{`{
+			"architecture": "x86",
+			"bitness": "64",
+			"brands": [
+				{"brand": "Google Chrome", "version": "119"},
+				{"brand": "Chromium", "version": "119"},
+				{"brand": "Not?A_Brand", "version": "24"}
+			],
+			"fullVersionList": [
+				{"brand": "Google Chrome", "version": "119.0.6045.160"},
+				{"brand": "Chromium", "version": "119.0.6045.160"},
+				{"brand": "Not?A_Brand", "version": "24.0.0.0"}
+			],
+			"mobile": false,
+			"model": "",
+			"platform": "Windows",
+			"platformVersion": "15.0.0"
+		}`, UserAgent{BrowserName: "Chrome", BrowserVersion: "119", OSName: "Windows", OSVersion: "11", OSFromHints: true}, ""},
+		{`{
+			"brands": [
+				{"brand": "Not_A Brand", "version": "8"},
+				{"brand": "Chromium", "version": "120"},
+				{"brand": "Google Chrome", "version": "120"}
+			],
+			"mobile": true,
+			"model": "Pixel 7",
+			"platform": "Android",
+			"platformVersion": "14.0.0"
+		}`, UserAgent{BrowserName: "Chrome", BrowserVersion: "120", OSName: "Android", OSVersion: "14", Model: "Pixel 7", OSFromHints: true}, ""},
+		{`[`, UserAgent{}, "unexpected end of JSON input"},
+	}
+
+	for i, tt := range tests {
+		t.Run(fmt.Sprintf("%d", i), func(t *testing.T) {
+			got, err := ParseUAData([]byte(tt.in))
+			if !errorContains(err, tt.wantErr) {
+				t.Fatalf("wrong error\ngot:  %v\nwant: %v", err, tt.wantErr)
+			}
+			if got != tt.want {
+				t.Errorf("\ngot:  %#v\nwant: %#v", got, tt.want)
+			}
+		})
+	}
+}
+
+func errorContains(err error, want string) bool {
+	if err == nil {
+		return want == ""
+	}
+	if want == "" {
+		return false
+	}
+	return strings.Contains(err.Error(), want)
+}
@@ -0,0 +1,130 @@
+package gadget
+
+// BrowserID is a stable identifier for a browser.
+//
+// Unlike the BrowserName this will never change, so it's safe to use as a key
+// in a database. Browsers not in this list (such as the product name of
+// unknown browsers) are BrowserUnknown.
+type BrowserID string
+
+// OSID is a stable identifier for an operating system.
+//
+// Unlike the OSName this will never change, so it's safe to use as a key in a
+// database.
+type OSID string
+
+// Browser identifiers; these values will never change.
+const (
+	BrowserUnknown          BrowserID = ""
+	BrowserChrome           BrowserID = "chrome"
+	BrowserEdge             BrowserID = "edge"
+	BrowserFirefox          BrowserID = "firefox"
+	BrowserInternetExplorer BrowserID = "internet-explorer"
+	BrowserOpera            BrowserID = "opera"
+	BrowserOperaMini        BrowserID = "opera-mini"
+	BrowserSafari           BrowserID = "safari"
+	BrowserBingPreview      BrowserID = "bing-preview"
+	BrowserPhantomJS        BrowserID = "phantomjs"
+	BrowserDillo            BrowserID = "dillo"
+	BrowserPaleMoon         BrowserID = "pale-moon"
+	BrowserBasilisk         BrowserID = "basilisk"
+)
+
+// OS identifiers; these values will never change.
+const (
+	OSUnknown      OSID = ""
+	OSAndroid      OSID = "android"
+	OSChromeOS     OSID = "chrome-os"
+	OSDragonFlyBSD OSID = "dragonfly-bsd"
+	OSFreeBSD      OSID = "freebsd"
+	OSFuchsia      OSID = "fuchsia"
+	OSHaiku        OSID = "haiku"
+	OSIOS          OSID = "ios"
+	OSJavaME       OSID = "java-me"
+	OSKaiOS        OSID = "kaios"
+	OSLinux        OSID = "linux"
+	OSMacOS        OSID = "macos"
+	OSMAUIRuntime  OSID = "maui-runtime"
+	OSNetBSD       OSID = "netbsd"
+	OSOpenBSD      OSID = "openbsd"
+	OSPlayStation4 OSID = "playstation-4"
+	OSSailfish     OSID = "sailfish"
+	OSSunOS        OSID = "sunos"
+	OSTizen        OSID = "tizen"
+	OSWindows      OSID = "windows"
+	OSWindowsPhone OSID = "windows-phone"
+)
+
+// The names ParseUA() uses for every ID.
+var (
+	browserNames = map[BrowserID]string{
+		BrowserChrome:           "Chrome",
+		BrowserEdge:             "Edge",
+		BrowserFirefox:          "Firefox",
+		BrowserInternetExplorer: "Internet Explorer",
+		BrowserOpera:            "Opera",
+		BrowserOperaMini:        "Opera Mini",
+		BrowserSafari:           "Safari",
+		BrowserBingPreview:      "BingPreview",
+		BrowserPhantomJS:        "PhantomJS",
+		BrowserDillo:            "Dillo",
+		BrowserPaleMoon:         "PaleMoon",
+		BrowserBasilisk:         "Basilisk",
+	}
+	osNames = map[OSID]string{
+		OSAndroid:      "Android",
+		OSChromeOS:     "Chrome OS",
+		OSDragonFlyBSD: "DragonFly BSD",
+		OSFreeBSD:      "FreeBSD",
+		OSFuchsia:      "Fuchsia",
+		OSHaiku:        "Haiku",
+		OSIOS:          "iOS",
+		OSJavaME:       "Java ME",
+		OSKaiOS:        "KaiOS",
+		OSLinux:        "Linux",
+		OSMacOS:        "macOS",
+		OSMAUIRuntime:  "MAUI Runtime",
+		OSNetBSD:       "NetBSD",
+		OSOpenBSD:      "OpenBSD",
+		OSPlayStation4: "PlayStation 4",
+		OSSailfish:     "Sailfish",
+		OSSunOS:        "SunOS",
+		OSTizen:        "Tizen",
+		OSWindows:      "Windows",
+		OSWindowsPhone: "Windows Phone",
+	}
+)
+
+// BrowserID gets the stable identifier for the BrowserName.
+func (u UserAgent) BrowserID() BrowserID {
+	for id, n := range browserNames {
+		if n == u.BrowserName {
+			return id
+		}
+	}
+	return BrowserUnknown
+}
+
+// OSID gets the stable identifier for the OSName.
+func (u UserAgent) OSID() OSID {
+	for id, n := range osNames {
+		if n == u.OSName {
+			return id
+		}
+	}
+	return OSUnknown
+}
+
+// IsKnown reports if this is one of the Browser* constants, other than
+// BrowserUnknown.
+func (id BrowserID) IsKnown() bool { _, ok := browserNames[id]; return ok }
+
+// IsKnown reports if this is one of the OS* constants, other than OSUnknown.
+func (id OSID) IsKnown() bool { _, ok := osNames[id]; return ok }
+
+// Name gets the BrowserName ParseUA() uses for this browser, or "" if it's not
+// known.
+func (id BrowserID) Name() string { return browserNames[id] }
+
+// Name gets the OSName ParseUA() uses for this OS, or "" if it's not known.
+func (id OSID) Name() string { return osNames[id] }
@@ -0,0 +1,64 @@
+package gadget
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestIDs(t *testing.T) {
+	ua := ParseUA("Mozilla/5.0 (Windows NT 10.0; Win64; x64; rv:73.0) Gecko/20100101 Firefox/73.0")
+	if ua.BrowserID() != BrowserFirefox || ua.OSID() != OSWindows {
+		t.Errorf("wrong IDs: %q %q", ua.BrowserID(), ua.OSID())
+	}
+
+	ua = ParseUA("curl/7.0")
+	if ua.BrowserID() != BrowserUnknown || ua.BrowserID().IsKnown() {
+		t.Errorf("wrong ID for curl: %q", ua.BrowserID())
+	}
+	if ua.OSID() != OSUnknown || ua.OSID().IsKnown() {
+		t.Errorf("wrong ID for curl: %q", ua.OSID())
+	}
+
+	for id, n := range browserNames {
+		if !id.IsKnown() || (UserAgent{BrowserName: n}).BrowserID() != id || id.Name() != n {
+			t.Errorf("%q doesn't round-trip", id)
+		}
+	}
+	for id, n := range osNames {
+		if !id.IsKnown() || (UserAgent{OSName: n}).OSID() != id || id.Name() != n {
+			t.Errorf("%q doesn't round-trip", id)
+		}
+	}
+}
+
+// Make sure that every name ParseUA() and the Client Hints set has an ID.
+func TestIDsComplete(t *testing.T) {
+	for _, h := range testHeaders(t) {
+		ua := ParseUA(h)
+		// Unknown browsers get the name from the first product, which don't
+		// have an ID.
+		if ua.BrowserName != "" && !strings.HasPrefix(h, ua.BrowserName+"/") && !ua.BrowserID().IsKnown() {
+			t.Errorf("no BrowserID for %q in %q", ua.BrowserName, h)
+		}
+		if ua.OSName != "" && !ua.OSID().IsKnown() {
+			t.Errorf("no OSID for %q in %q", ua.OSName, h)
+		}
+	}
+
+	for _, p := range []string{"Windows", "macOS", "iOS", "Android", "Chrome OS", "Chromium OS"} {
+		ua := fromHints([]brand{{name: "Chromium", version: "120"}}, p, "1.0", "")
+		if !ua.BrowserID().IsKnown() {
+			t.Errorf("no BrowserID for %q", ua.BrowserName)
+		}
+		if !ua.OSID().IsKnown() {
+			t.Errorf("no OSID for %q", ua.OSName)
+		}
+	}
+
+	for _, k := range knownBrowsers {
+		k = k[:len(k)-1]
+		if !(UserAgent{BrowserName: k}).BrowserID().IsKnown() {
+			t.Errorf("no BrowserID for %q", k)
+		}
+	}
+}
@@ -0,0 +1,174 @@
+package gadget
+
+import (
+	"database/sql/driver"
+	"encoding/json"
+	"fmt"
+	"strings"
+)
+
+var (
+	textEscaper   = strings.NewReplacer(`\`, `\\`, "\t", `\t`, "\n", `\n`)
+	textUnescaper = strings.NewReplacer(`\\`, `\`, `\t`, "\t", `\n`, "\n", `\{`, "{")
+)
+
+// The JSON representation; don't change the field names, as they're stored in
+// the database.
+type jsonUserAgent struct {
+	BrowserName    string `json:"browser_name"`
+	BrowserVersion string `json:"browser_version"`
+	OSName         string `json:"os_name"`
+	OSVersion      string `json:"os_version"`
+	Model          string `json:"model,omitempty"`
+	App            string `json:"app,omitempty"`
+	Reduced        bool   `json:"reduced,omitempty"`
+	OSFromHints    bool   `json:"os_from_hints,omitempty"`
+	Duplicate      bool   `json:"duplicate,omitempty"`
+}
+
+// MarshalJSON encodes the UserAgent as a JSON object; the field names are
+// browser_name, browser_version, os_name, os_version, model, app, reduced,
+// os_from_hints, and duplicate. The last five are omitted if they're empty.
+func (u UserAgent) MarshalJSON() ([]byte, error) {
+	return json.Marshal(jsonUserAgent{
+		BrowserName: u.BrowserName, BrowserVersion: u.BrowserVersion,
+		OSName: u.OSName, OSVersion: u.OSVersion,
+		Model: u.Model, App: u.App,
+		Reduced: u.Reduced, OSFromHints: u.OSFromHints, Duplicate: u.Duplicate,
+	})
+}
+
+// UnmarshalJSON decodes the output of MarshalJSON().
+func (u *UserAgent) UnmarshalJSON(data []byte) error {
+	var j jsonUserAgent
+	err := json.Unmarshal(data, &j)
+	if err != nil {
+		return err
+	}
+	*u = UserAgent{
+		BrowserName: j.BrowserName, BrowserVersion: j.BrowserVersion,
+		OSName: j.OSName, OSVersion: j.OSVersion,
+		Model: j.Model, App: j.App,
+		Reduced: j.Reduced, OSFromHints: j.OSFromHints, Duplicate: j.Duplicate,
+	}
+	return nil
+}
+
+// MarshalText encodes the UserAgent as Tab-separated values:
+//
+//	<browser name>\t<browser version>\t<os name>\t<os version>\t<model>\t<app>\t<flags>
+//
+// Trailing empty values are omitted, so this is usually just the first four
+// values. The flags are "r" for Reduced, "h" for OSFromHints, and "d" for
+// Duplicate. Tabs, newlines, and backslashes in the values are escaped as \t,
+// \n, and \\. A "{" at the start is escaped as \{, so Scan() doesn't confuse it
+// with the JSON encoding.
+func (u UserAgent) MarshalText() ([]byte, error) {
+	var flags string
+	if u.Reduced {
+		flags += "r"
+	}
+	if u.OSFromHints {
+		flags += "h"
+	}
+	if u.Duplicate {
+		flags += "d"
+	}
+
+	fields := []string{u.BrowserName, u.BrowserVersion, u.OSName, u.OSVersion, u.Model, u.App, flags}
+	for len(fields) > 0 && fields[len(fields)-1] == "" {
+		fields = fields[:len(fields)-1]
+	}
+	for i := range fields {
+		fields[i] = textEscaper.Replace(fields[i])
+	}
+	if len(fields) > 0 && strings.HasPrefix(fields[0], "{") {
+		fields[0] = `\` + fields[0]
+	}
+	return []byte(strings.Join(fields, "\t")), nil
+}
+
+// UnmarshalText decodes the output of MarshalText().
+func (u *UserAgent) UnmarshalText(text []byte) error {
+	fields := strings.Split(string(text), "\t")
+	if len(fields) > 7 {
+		return fmt.Errorf("gadget.UserAgent.UnmarshalText: too many fields (%d) in %q", len(fields), text)
+	}
+	for len(fields) < 7 {
+		fields = append(fields, "")
+	}
+	for i := range fields {
+		fields[i] = textUnescaper.Replace(fields[i])
+	}
+
+	n := UserAgent{
+		BrowserName: fields[0], BrowserVersion: fields[1],
+		OSName: fields[2], OSVersion: fields[3],
+		Model: fields[4], App: fields[5],
+	}
+	for _, f := range fields[6] {
+		switch f {
+		case 'r':
+			n.Reduced = true
+		case 'h':
+			n.OSFromHints = true
+		case 'd':
+			n.Duplicate = true
+		default:
+			return fmt.Errorf("gadget.UserAgent.UnmarshalText: unknown flag %q in %q", f, text)
+		}
+	}
+	*u = n
+	return nil
+}
+
+// Value implements the SQL Value function to determine what to store in the
+// database; this is always the MarshalText() encoding, even though Scan()
+// accepts JSON. Use UserAgentJSON to store it in a json or jsonb column.
+func (u UserAgent) Value() (driver.Value, error) {
+	t, err := u.MarshalText()
+	return string(t), err
+}
+
+// Scan converts the data from the database; this accepts both the
+// MarshalText() and MarshalJSON() encodings, so it can be used with text and
+// JSON columns.
+func (u *UserAgent) Scan(v interface{}) error {
+	var b []byte
+	switch vv := v.(type) {
+	case nil:
+		*u = UserAgent{}
+		return nil
+	case []byte:
+		b = vv
+	case string:
+		b = []byte(vv)
+	default:
+		return fmt.Errorf("gadget.UserAgent.Scan: unsupported type: %T", v)
+	}
+
+	if len(b) > 0 && b[0] == '{' {
+		return u.UnmarshalJSON(b)
+	}
+	return u.UnmarshalText(b)
+}
+
+// UserAgentJSON stores a UserAgent as JSON in the database, for json and jsonb
+// columns:
+//
+//	db.Exec(`insert into hits (ua) values ($1)`, gadget.UserAgentJSON{ua})
+//
+//	var ua gadget.UserAgentJSON
+//	db.QueryRow(`select ua from hits`).Scan(&ua)
+type UserAgentJSON struct{ UserAgent }
+
+// Value implements the SQL Value function to determine what to store in the
+// database; this is the MarshalJSON() encoding.
+func (u UserAgentJSON) Value() (driver.Value, error) {
+	j, err := u.MarshalJSON()
+	return string(j), err
+}
+
+// Scan converts the data from the database; like UserAgent.Scan() this accepts
+// both the MarshalText() and MarshalJSON() encodings.
+func (u *UserAgentJSON) Scan(v interface{}) error { return u.UserAgent.Scan(v) }
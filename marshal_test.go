@@ -0,0 +1,130 @@
+package gadget
+
+import (
+	"encoding/json"
+	"fmt"
+	"testing"
+)
+
+func TestMarshal(t *testing.T) {
+	tests := []struct {
+		in       UserAgent
+		wantJSON string
+		wantText string
+	}{
+		{UserAgent{},
+			`{"browser_name":"","browser_version":"","os_name":"","os_version":""}`,
+			``},
+		{UserAgent{BrowserName: "Firefox", BrowserVersion: "73", OSName: "Windows", OSVersion: "10"},
+			`{"browser_name":"Firefox","browser_version":"73","os_name":"Windows","os_version":"10"}`,
+			"Firefox\t73\tWindows\t10"},
+		{UserAgent{OSName: "Linux"},
+			`{"browser_name":"","browser_version":"","os_name":"Linux","os_version":""}`,
+			"\t\tLinux"},
+		{UserAgent{BrowserName: "Chrome", BrowserVersion: "118", OSName: "Android", OSVersion: "10", Reduced: true, Duplicate: true},
+			`{"browser_name":"Chrome","browser_version":"118","os_name":"Android","os_version":"10","reduced":true,"duplicate":true}`,
+			"Chrome\t118\tAndroid\t10\t\t\trd"},
+		{UserAgent{BrowserName: "Chrome", BrowserVersion: "118", OSName: "Windows", OSVersion: "11", OSFromHints: true},
+			`{"browser_name":"Chrome","browser_version":"118","os_name":"Windows","os_version":"11","os_from_hints":true}`,
+			"Chrome\t118\tWindows\t11\t\t\th"},
+		{UserAgent{BrowserName: "Chrome", BrowserVersion: "118", OSName: "Android", OSVersion: "13", Model: "Pixel 7", App: "com.example.app"},
+			`{"browser_name":"Chrome","browser_version":"118","os_name":"Android","os_version":"13","model":"Pixel 7","app":"com.example.app"}`,
+			"Chrome\t118\tAndroid\t13\tPixel 7\tcom.example.app"},
+		{UserAgent{BrowserName: "{x", BrowserVersion: "{1}"},
+			`{"browser_name":"{x","browser_version":"{1}","os_name":"","os_version":""}`,
+			`\{x` + "\t{1}"},
+		{UserAgent{BrowserName: `\{`},
+			`{"browser_name":"\\{","browser_version":"","os_name":"","os_version":""}`,
+			`\\{`},
+		{UserAgent{BrowserName: "Tab\tNew\nline", BrowserVersion: `back\slash\t`},
+			`{"browser_name":"Tab\tNew\nline","browser_version":"back\\slash\\t","os_name":"","os_version":""}`,
+			`Tab\tNew\nline` + "\t" + `back\\slash\\t`},
+	}
+
+	for i, tt := range tests {
+		t.Run(fmt.Sprintf("%d", i), func(t *testing.T) {
+			j, err := json.Marshal(tt.in)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if string(j) != tt.wantJSON {
+				t.Errorf("JSON\ngot:  %s\nwant: %s", j, tt.wantJSON)
+			}
+			var fromJSON UserAgent
+			if err := json.Unmarshal(j, &fromJSON); err != nil {
+				t.Fatal(err)
+			}
+			if fromJSON != tt.in {
+				t.Errorf("JSON round-trip\ngot:  %#v\nwant: %#v", fromJSON, tt.in)
+			}
+
+			text, err := tt.in.MarshalText()
+			if err != nil {
+				t.Fatal(err)
+			}
+			if string(text) != tt.wantText {
+				t.Errorf("text\ngot:  %q\nwant: %q", text, tt.wantText)
+			}
+			var fromText UserAgent
+			if err := fromText.UnmarshalText(text); err != nil {
+				t.Fatal(err)
+			}
+			if fromText != tt.in {
+				t.Errorf("text round-trip\ngot:  %#v\nwant: %#v", fromText, tt.in)
+			}
+
+			v, err := tt.in.Value()
+			if err != nil {
+				t.Fatal(err)
+			}
+			for _, src := range []interface{}{v, []byte(v.(string)), string(j)} {
+				var scanned UserAgent
+				if err := scanned.Scan(src); err != nil {
+					t.Fatal(err)
+				}
+				if scanned != tt.in {
+					t.Errorf("Scan(%T)\ngot:  %#v\nwant: %#v", src, scanned, tt.in)
+				}
+			}
+
+			v, err = UserAgentJSON{tt.in}.Value()
+			if err != nil {
+				t.Fatal(err)
+			}
+			if v != tt.wantJSON {
+				t.Errorf("UserAgentJSON.Value\ngot:  %s\nwant: %s", v, tt.wantJSON)
+			}
+			var scanned UserAgentJSON
+			if err := scanned.Scan(v); err != nil {
+				t.Fatal(err)
+			}
+			if scanned.UserAgent != tt.in {
+				t.Errorf("UserAgentJSON.Scan\ngot:  %#v\nwant: %#v", scanned.UserAgent, tt.in)
+			}
+		})
+	}
+}
+
+func TestUnmarshalTextError(t *testing.T) {
+	tests := []struct {
+		in, wantErr string
+	}{
+		{"a\tb\tc\td\te\tf\tr\th", "too many fields"},
+		{"a\tb\tc\td\te\tf\tx", "unknown flag 'x'"},
+	}
+
+	for i, tt := range tests {
+		t.Run(fmt.Sprintf("%d", i), func(t *testing.T) {
+			var u UserAgent
+			err := u.UnmarshalText([]byte(tt.in))
+			if !errorContains(err, tt.wantErr) {
+				t.Errorf("\ngot:  %v\nwant: %v", err, tt.wantErr)
+			}
+		})
+	}
+
+	var u UserAgent
+	if err := u.Scan(42); !errorContains(err, "unsupported type: int") {
+		t.Errorf("wrong error: %v", err)
+	}
+}
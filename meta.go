@@ -0,0 +1,74 @@
+package gadget
+
+// Meta is metadata for a browser or operating system.
+type Meta struct {
+	Name     string // Name as ParseUA() uses it.
+	Vendor   string // Company or project behind it; may be blank.
+	Homepage string // Homepage URL.
+
+	// Browser engine family: "Blink", "Gecko", "WebKit", "EdgeHTML",
+	// "Trident", "Presto", "Goanna", or "Dillo". Always blank for operating
+	// systems.
+	Engine string
+
+	// Icon slug in the Simple Icons set (https://simpleicons.org), or blank if
+	// there is no icon in that set.
+	Icon string
+}
+
+var (
+	browserMeta = map[BrowserID]Meta{
+		BrowserChrome:           {"Chrome", "Google", "https://www.google.com/chrome/", "Blink", "googlechrome"},
+		BrowserEdge:             {"Edge", "Microsoft", "https://www.microsoft.com/edge", "EdgeHTML", "microsoftedge"},
+		BrowserFirefox:          {"Firefox", "Mozilla", "https://www.mozilla.org/firefox/", "Gecko", "firefoxbrowser"},
+		BrowserInternetExplorer: {"Internet Explorer", "Microsoft", "https://en.wikipedia.org/wiki/Internet_Explorer", "Trident", "internetexplorer"},
+		BrowserOpera:            {"Opera", "Opera", "https://www.opera.com/", "Presto", "opera"},
+		BrowserOperaMini:        {"Opera Mini", "Opera", "https://www.opera.com/mobile/mini", "Presto", "opera"},
+		BrowserSafari:           {"Safari", "Apple", "https://www.apple.com/safari/", "WebKit", "safari"},
+		BrowserBingPreview:      {"BingPreview", "Microsoft", "https://www.bing.com/", "", "microsoftbing"},
+		BrowserPhantomJS:        {"PhantomJS", "", "https://phantomjs.org/", "WebKit", ""},
+		BrowserDillo:            {"Dillo", "", "https://dillo-browser.github.io/", "Dillo", ""},
+		BrowserPaleMoon:         {"PaleMoon", "Moonchild Productions", "https://www.palemoon.org/", "Goanna", ""},
+		BrowserBasilisk:         {"Basilisk", "Moonchild Productions", "https://www.basilisk-browser.org/", "Goanna", ""},
+	}
+	osMeta = map[OSID]Meta{
+		OSAndroid:      {"Android", "Google", "https://www.android.com/", "", "android"},
+		OSChromeOS:     {"Chrome OS", "Google", "https://www.google.com/chromebook/chrome-os/", "", "googlechrome"},
+		OSDragonFlyBSD: {"DragonFly BSD", "", "https://www.dragonflybsd.org/", "", ""},
+		OSFreeBSD:      {"FreeBSD", "The FreeBSD Project", "https://www.freebsd.org/", "", "freebsd"},
+		OSFuchsia:      {"Fuchsia", "Google", "https://fuchsia.dev/", "", ""},
+		OSHaiku:        {"Haiku", "Haiku, Inc.", "https://www.haiku-os.org/", "", ""},
+		OSIOS:          {"iOS", "Apple", "https://www.apple.com/ios/", "", "ios"},
+		OSJavaME:       {"Java ME", "Oracle", "https://www.oracle.com/java/technologies/javameoverview.html", "", ""},
+		OSKaiOS:        {"KaiOS", "KaiOS Technologies", "https://www.kaiostech.com/", "", ""},
+		OSLinux:        {"Linux", "", "https://www.kernel.org/", "", "linux"},
+		OSMacOS:        {"macOS", "Apple", "https://www.apple.com/macos/", "", "macos"},
+		OSMAUIRuntime:  {"MAUI Runtime", "MediaTek", "https://en.wikipedia.org/wiki/MAUI_Runtime_Environment", "", ""},
+		OSNetBSD:       {"NetBSD", "The NetBSD Foundation", "https://www.netbsd.org/", "", "netbsd"},
+		OSOpenBSD:      {"OpenBSD", "The OpenBSD Project", "https://www.openbsd.org/", "", "openbsd"},
+		OSPlayStation4: {"PlayStation 4", "Sony", "https://www.playstation.com/", "", "playstation4"},
+		OSSailfish:     {"Sailfish", "Jolla", "https://sailfishos.org/", "", ""},
+		OSSunOS:        {"SunOS", "Oracle", "https://www.oracle.com/solaris/", "", ""},
+		OSTizen:        {"Tizen", "The Linux Foundation", "https://www.tizen.org/", "", ""},
+		OSWindows:      {"Windows", "Microsoft", "https://www.microsoft.com/windows/", "", "windows"},
+		OSWindowsPhone: {"Windows Phone", "Microsoft", "https://en.wikipedia.org/wiki/Windows_Phone", "", "windows"},
+	}
+)
+
+// Meta gets the metadata for this browser; ok is false if there is none.
+func (id BrowserID) Meta() (m Meta, ok bool) { m, ok = browserMeta[id]; return m, ok }
+
+// Meta gets the metadata for this OS; ok is false if there is none.
+func (id OSID) Meta() (m Meta, ok bool) { m, ok = osMeta[id]; return m, ok }
+
+// BrowserMeta gets the metadata for a BrowserName as ParseUA() returns it; ok
+// is false if there is none.
+func BrowserMeta(name string) (Meta, bool) {
+	return UserAgent{BrowserName: name}.BrowserID().Meta()
+}
+
+// OSMeta gets the metadata for an OSName as ParseUA() returns it; ok is false
+// if there is none.
+func OSMeta(name string) (Meta, bool) {
+	return UserAgent{OSName: name}.OSID().Meta()
+}
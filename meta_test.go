@@ -0,0 +1,34 @@
+package gadget
+
+import "testing"
+
+func TestMeta(t *testing.T) {
+	for id, n := range browserNames {
+		m, ok := BrowserMeta(n)
+		if !ok {
+			t.Errorf("no metadata for %q", id)
+		}
+		if m.Name != n || m.Homepage == "" || m.Engine == "" && id != BrowserBingPreview {
+			t.Errorf("incomplete metadata for %q: %#v", id, m)
+		}
+	}
+	for id, n := range osNames {
+		m, ok := OSMeta(n)
+		if !ok {
+			t.Errorf("no metadata for %q", id)
+		}
+		if m.Name != n || m.Homepage == "" || m.Engine != "" {
+			t.Errorf("wrong metadata for %q: %#v", id, m)
+		}
+	}
+	if len(browserMeta) != len(browserNames) || len(osMeta) != len(osNames) {
+		t.Errorf("metadata for unknown IDs")
+	}
+
+	if _, ok := BrowserMeta("curl"); ok {
+		t.Error("metadata for curl")
+	}
+	if m, _ := BrowserFirefox.Meta(); m.Icon != "firefoxbrowser" {
+		t.Errorf("wrong icon: %q", m.Icon)
+	}
+}
@@ -0,0 +1,159 @@
+package gadget
+
+import (
+	"context"
+	"net/http"
+	"strings"
+)
+
+// The high-entropy Client Hints needed to get the actual OS version and device
+// for reduced User-Agents.
+const acceptCH = "Sec-CH-UA-Platform-Version, Sec-CH-UA-Full-Version-List, Sec-CH-UA-Model"
+
+type ctxKey struct{}
+
+// NewContext returns a copy of ctx with the UserAgent stored in it.
+func NewContext(ctx context.Context, ua UserAgent) context.Context {
+	return context.WithValue(ctx, ctxKey{}, ua)
+}
+
+// FromContext gets the UserAgent stored in the context by Middleware() or
+// NewContext().
+//
+// This returns an empty UserAgent if there is none.
+func FromContext(ctx context.Context) UserAgent {
+	ua, _ := ctx.Value(ctxKey{}).(UserAgent)
+	return ua
+}
+
+// Middleware parses every request with Parse() and stores the result in the
+// request context; use FromContext() to get it.
+//
+// It also asks the browser to send the Client Hints needed to resolve reduced
+// User-Agents with the Accept-CH header. Browsers only send these on
+// subsequent requests, unless critical is set: this also sends them as
+// Critical-CH, which makes the browser retry the request with the hints if
+// they were missing (at the cost of an extra request).
+//
+// The hints are added to any Accept-CH, Critical-CH, and Vary headers that are
+// already set, and are always added to Vary as the response may depend on
+// them.
+func Middleware(critical bool) func(http.Handler) http.Handler {
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			h := w.Header()
+			addHeaderList(h, "Accept-CH", acceptCH)
+			addHeaderList(h, "Vary", acceptCH)
+			if critical {
+				addHeaderList(h, "Critical-CH", acceptCH)
+			}
+
+			next.ServeHTTP(w, r.WithContext(NewContext(r.Context(), Parse(r.Header))))
+		})
+	}
+}
+
+// Add the comma-separated values in add to the header key, skipping any
+// values that are already in it.
+func addHeaderList(h http.Header, key, add string) {
+	var list []string
+	seen := make(map[string]bool)
+	for _, v := range append(h[http.CanonicalHeaderKey(key)], add) {
+		for _, f := range strings.Split(v, ",") {
+			f = strings.TrimSpace(f)
+			if f != "" && !seen[strings.ToLower(f)] {
+				seen[strings.ToLower(f)] = true
+				list = append(list, f)
+			}
+		}
+	}
+	h.Set(key, strings.Join(list, ", "))
+}
+
+// Unsupported is a middleware for browsers older than a minimum version.
+//
+// Crawlers, libraries, and unknown User-Agents are exempt, as are all browsers
+// not listed in Min.
+type Unsupported struct {
+	// Minimum versions for every BrowserName; for example:
+	//
+	//	map[string]gadget.Version{"Safari": {Major: 14}, "Firefox": {Major: 78}}
+	Min map[string]Version
+
+	// Rewrite the request path to this page for unsupported browsers, for
+	// example "/upgrade-browser".
+	Rewrite string
+
+	// Set this response header for unsupported browsers; the value is the
+	// browser, for example "Safari 12.1".
+	Header string
+
+	// Called for unsupported browsers. The request isn't passed to the next
+	// handler if this returns true.
+	Func func(w http.ResponseWriter, r *http.Request, ua UserAgent) bool
+
+	// Report if the request is from a crawler, which is always exempt.
+	//
+	// The default treats User-Agents with "bot", "crawl", "spider", or a URL
+	// in them as a crawler, which is what most crawlers send (e.g.
+	// "(compatible; Googlebot/2.1; +http://www.google.com/bot.html)"). Set
+	// this to use something more thorough, such as zgo.at/isbot:
+	//
+	//	IsCrawler: func(r *http.Request) bool { return isbot.Is(isbot.Bot(r)) }
+	IsCrawler func(r *http.Request) bool
+}
+
+// Handler returns the middleware.
+//
+// This uses the UserAgent from the request context if Middleware() was used
+// before this, or calls Parse() if it wasn't.
+func (u Unsupported) Handler(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		ua, ok := r.Context().Value(ctxKey{}).(UserAgent)
+		if !ok {
+			ua = Parse(r.Header)
+		}
+		if !u.unsupported(ua, r) {
+			next.ServeHTTP(w, r)
+			return
+		}
+
+		if u.Header != "" {
+			w.Header().Set(u.Header, ua.Browser())
+		}
+		if u.Func != nil && u.Func(w, r, ua) {
+			return
+		}
+		if u.Rewrite != "" {
+			r = r.Clone(r.Context())
+			r.URL.Path, r.URL.RawPath = u.Rewrite, ""
+		}
+		next.ServeHTTP(w, r)
+	})
+}
+
+func (u Unsupported) unsupported(ua UserAgent, r *http.Request) bool {
+	min, ok := u.Min[ua.BrowserName]
+	v := ua.BrowserVer()
+	if !ok || v.IsZero() {
+		return false
+	}
+	if !v.Less(min) {
+		return false
+	}
+	if u.IsCrawler != nil {
+		return !u.IsCrawler(r)
+	}
+	return !isCrawler(r)
+}
+
+// The default for Unsupported.IsCrawler.
+func isCrawler(r *http.Request) bool {
+	ua := strings.ToLower(r.Header.Get("User-Agent"))
+	for _, s := range []string{"bot", "crawl", "spider", "http://", "https://"} {
+		if strings.Contains(ua, s) {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,137 @@
+package gadget
+
+import (
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+)
+
+func TestMiddleware(t *testing.T) {
+	for _, critical := range []bool{false, true} {
+		var got UserAgent
+		handler := Middleware(critical)(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			got = FromContext(r.Context())
+		}))
+
+		r := httptest.NewRequest("GET", "/", nil)
+		r.Header.Set("User-Agent", "Mozilla/5.0 (Windows NT 10.0; Win64; x64; rv:73.0) Gecko/20100101 Firefox/73.0")
+		rr := httptest.NewRecorder()
+		handler.ServeHTTP(rr, r)
+
+		if got.String() != "Firefox 73 on Windows 10" {
+			t.Errorf("wrong UserAgent in context: %q", got)
+		}
+		if h := rr.Header().Get("Accept-CH"); h != acceptCH {
+			t.Errorf("wrong Accept-CH: %q", h)
+		}
+		if h := rr.Header().Get("Vary"); h != acceptCH {
+			t.Errorf("wrong Vary: %q", h)
+		}
+		if h := rr.Header().Get("Critical-CH"); (h != "") != critical {
+			t.Errorf("wrong Critical-CH: %q", h)
+		}
+	}
+}
+
+func TestMiddlewareMerge(t *testing.T) {
+	outer := func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			w.Header().Set("Accept-CH", "Sec-CH-UA-Arch, sec-ch-ua-model")
+			w.Header().Add("Vary", "Accept-Encoding")
+			next.ServeHTTP(w, r)
+		})
+	}
+	handler := outer(Middleware(true)(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {})))
+
+	rr := httptest.NewRecorder()
+	handler.ServeHTTP(rr, httptest.NewRequest("GET", "/", nil))
+
+	tests := []struct {
+		header, want string
+	}{
+		{"Accept-CH", "Sec-CH-UA-Arch, sec-ch-ua-model, Sec-CH-UA-Platform-Version, Sec-CH-UA-Full-Version-List"},
+		{"Critical-CH", acceptCH},
+		{"Vary", "Accept-Encoding, " + acceptCH},
+	}
+	for i, tt := range tests {
+		t.Run(fmt.Sprintf("%d", i), func(t *testing.T) {
+			if got := strings.Join(rr.Header()[http.CanonicalHeaderKey(tt.header)], ", "); got != tt.want {
+				t.Errorf("\ngot:  %q\nwant: %q", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestFromContext(t *testing.T) {
+	r := httptest.NewRequest("GET", "/", nil)
+	if ua := FromContext(r.Context()); ua != (UserAgent{}) {
+		t.Errorf("not empty: %#v", ua)
+	}
+}
+
+func TestUnsupported(t *testing.T) {
+	const (
+		oldSafari = "Mozilla/5.0 (Macintosh; Intel Mac OS X 10_14_6) AppleWebKit/605.1.15 (KHTML, like Gecko) Version/12.1.2 Safari/605.1.15"
+		newSafari = "Mozilla/5.0 (Macintosh; Intel Mac OS X 10_15_7) AppleWebKit/605.1.15 (KHTML, like Gecko) Version/17.1 Safari/605.1.15"
+		oldBot    = "Mozilla/5.0 (Linux; Android 6.0.1; Nexus 5X Build/MMB29P) AppleWebKit/537.36 (KHTML, like Gecko) Chrome/41.0.2272.96 Mobile Safari/537.36 (compatible; Googlebot/2.1; +http://www.google.com/bot.html)"
+		oldFF     = "Mozilla/5.0 (Windows NT 6.1; rv:52.0) Gecko/20100101 Firefox/52.0"
+	)
+	var (
+		never  = func(*http.Request) bool { return false }
+		always = func(*http.Request) bool { return true }
+	)
+
+	tests := []struct {
+		ua         string
+		u          Unsupported
+		wantPath   string
+		wantHeader string
+		wantFunc   bool
+	}{
+		{newSafari, Unsupported{Rewrite: "/upgrade"}, "/", "", false},
+		{oldSafari, Unsupported{Rewrite: "/upgrade"}, "/upgrade", "", false},
+		{oldSafari, Unsupported{Header: "X-Unsupported"}, "/", "Safari 12.1", false},
+		{oldSafari, Unsupported{Func: func(http.ResponseWriter, *http.Request, UserAgent) bool { return true }}, "", "", true},
+		{oldSafari, Unsupported{Rewrite: "/upgrade", Func: func(http.ResponseWriter, *http.Request, UserAgent) bool { return false }}, "/upgrade", "", true},
+		{oldBot, Unsupported{Rewrite: "/upgrade"}, "/", "", false},
+		{oldBot, Unsupported{Rewrite: "/upgrade", IsCrawler: never}, "/upgrade", "", false},
+		{oldSafari, Unsupported{Rewrite: "/upgrade", IsCrawler: always}, "/", "", false},
+		{oldFF, Unsupported{Rewrite: "/upgrade"}, "/", "", false},
+		{"curl/7.0", Unsupported{Rewrite: "/upgrade"}, "/", "", false},
+		{"", Unsupported{Rewrite: "/upgrade"}, "/", "", false},
+	}
+
+	for i, tt := range tests {
+		t.Run(fmt.Sprintf("%d", i), func(t *testing.T) {
+			var gotPath, gotFunc = "", false
+			tt.u.Min = map[string]Version{"Safari": {Major: 14}, "Chrome": {Major: 80}}
+			if tt.u.Func != nil {
+				f := tt.u.Func
+				tt.u.Func = func(w http.ResponseWriter, r *http.Request, ua UserAgent) bool {
+					gotFunc = true
+					return f(w, r, ua)
+				}
+			}
+
+			handler := tt.u.Handler(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				gotPath = r.URL.Path
+			}))
+			r := httptest.NewRequest("GET", "/", nil)
+			r.Header.Set("User-Agent", tt.ua)
+			rr := httptest.NewRecorder()
+			handler.ServeHTTP(rr, r)
+
+			if gotPath != tt.wantPath {
+				t.Errorf("path\ngot:  %q\nwant: %q", gotPath, tt.wantPath)
+			}
+			if h := rr.Header().Get("X-Unsupported"); h != tt.wantHeader {
+				t.Errorf("header\ngot:  %q\nwant: %q", h, tt.wantHeader)
+			}
+			if gotFunc != tt.wantFunc {
+				t.Errorf("func\ngot:  %t\nwant: %t", gotFunc, tt.wantFunc)
+			}
+		})
+	}
+}
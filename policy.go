@@ -0,0 +1,336 @@
+package gadget
+
+import (
+	"fmt"
+	"regexp"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// Browser names in browserslist queries, mapped to the BrowserName ParseUA()
+// uses.
+var policyBrowsers = map[string]string{
+	"chrome":    "Chrome",
+	"and_chr":   "Chrome",
+	"chromium":  "Chrome",
+	"firefox":   "Firefox",
+	"ff":        "Firefox",
+	"and_ff":    "Firefox",
+	"safari":    "Safari",
+	"ios_saf":   "Safari",
+	"ios":       "Safari",
+	"edge":      "Edge",
+	"ie":        "Internet Explorer",
+	"explorer":  "Internet Explorer",
+	"opera":     "Opera",
+	"op_mini":   "Opera Mini",
+	"operamini": "Opera Mini",
+}
+
+var (
+	reSeparator = regexp.MustCompile(`(?i)\s*(,|\s+or\s+|\s+and\s+)\s*`)
+	reLast      = regexp.MustCompile(`^last\s+(\d+)\s+(?:(\w+)\s+)??(?:major\s+)?versions?$`)
+	reLastYears = regexp.MustCompile(`^last\s+(\d+(?:\.\d+)?)\s+years?$`)
+	reSince     = regexp.MustCompile(`^since\s+(\d{4}(?:-\d{2}(?:-\d{2})?)?)$`)
+	reCompare   = regexp.MustCompile(`^(\w+)\s*(>=|<=|>|<)\s*([\d.]+)$`)
+	reRange     = regexp.MustCompile(`^(\w+)\s+([\d.]+)\s*-\s*([\d.]+)$`)
+	reExact     = regexp.MustCompile(`^(\w+)\s+([\d.]+)$`)
+)
+
+// How a term is combined with the result of the previous terms.
+const (
+	combineOr = iota
+	combineAnd
+	combineNot
+)
+
+// Policy is a compiled browserslist query.
+type Policy struct {
+	query string
+	terms []policyTerm
+}
+
+type policyTerm struct {
+	combine int
+	match   func(name string, v Version) bool
+}
+
+// MustCompilePolicy is like CompilePolicy, but will panic on errors.
+func MustCompilePolicy(query string) *Policy {
+	p, err := CompilePolicy(query)
+	if err != nil {
+		panic(err)
+	}
+	return p
+}
+
+// CompilePolicy compiles a browserslist query, such as:
+//
+//	last 2 Chrome versions, Firefox ESR, Safari >= 14, not dead
+//
+// The query is evaluated against the release data embedded in gadget rather
+// than the caniuse data; queries that need usage statistics such as "> 0.5%"
+// or "defaults" are not supported. The supported queries are:
+//
+//	last 2 versions             Last two versions of every browser.
+//	last 2 Chrome versions      Last two versions of one browser.
+//	last 2 years                Versions released in the last two years.
+//	since 2020-05               Versions released since this date.
+//	Firefox ESR                 Supported Firefox Extended Support Releases.
+//	Safari >= 14                Version comparison; >, >=, <, and <= work.
+//	Safari 14                   Exact version.
+//	Safari 13-14                Range of versions, inclusive.
+//	dead                        Browsers that are no longer updated.
+//	all                         All browsers.
+//
+// Terms can be combined with "," or "or", "and", and "not".
+//
+// Versions newer than the latest release gadget knows about always match
+// "last" and "since" queries, even if the date is after the latest release.
+//
+// Note that the Chromium-based Edge and Opera are reported as Chrome, so Edge
+// and Opera only match the EdgeHTML and Presto versions.
+func CompilePolicy(query string) (*Policy, error) {
+	p := &Policy{query: query}
+
+	q := strings.TrimSpace(query)
+	if q == "" {
+		return nil, fmt.Errorf("gadget.CompilePolicy: empty query")
+	}
+
+	var (
+		seps    = reSeparator.FindAllStringIndex(q, -1)
+		start   = 0
+		combine = combineOr
+	)
+	for i := 0; i <= len(seps); i++ {
+		end := len(q)
+		if i < len(seps) {
+			end = seps[i][0]
+		}
+
+		t := strings.ToLower(strings.TrimSpace(q[start:end]))
+		c := combine
+		if strings.HasPrefix(t, "not ") {
+			if i == 0 {
+				return nil, fmt.Errorf("gadget.CompilePolicy: %q: query can't start with \"not\"", query)
+			}
+			c, t = combineNot, strings.TrimSpace(t[4:])
+		}
+
+		m, err := compileTerm(t)
+		if err != nil {
+			return nil, fmt.Errorf("gadget.CompilePolicy: %q: %w", query, err)
+		}
+		p.terms = append(p.terms, policyTerm{combine: c, match: m})
+
+		if i < len(seps) {
+			combine = combineOr
+			if strings.EqualFold(strings.TrimSpace(q[seps[i][0]:seps[i][1]]), "and") {
+				combine = combineAnd
+			}
+			start = seps[i][1]
+		}
+	}
+	return p, nil
+}
+
+// String returns the query this Policy was compiled from.
+func (p *Policy) String() string { return p.query }
+
+// Allows reports if the browser is allowed by this policy.
+//
+// This always returns false if there is no BrowserName. Terms with a version,
+// such as "Safari < 14" or "last 2 versions", never match if the version is
+// unknown.
+func (p *Policy) Allows(ua UserAgent) bool {
+	if ua.BrowserName == "" {
+		return false
+	}
+
+	var (
+		v  = ua.BrowserVer()
+		ok bool
+	)
+	for _, t := range p.terms {
+		switch t.combine {
+		case combineOr:
+			ok = ok || t.match(ua.BrowserName, v)
+		case combineAnd:
+			ok = ok && t.match(ua.BrowserName, v)
+		case combineNot:
+			ok = ok && !t.match(ua.BrowserName, v)
+		}
+	}
+	return ok
+}
+
+func compileTerm(t string) (func(string, Version) bool, error) {
+	switch t {
+	case "":
+		return nil, fmt.Errorf("empty term")
+	case "all":
+		return func(string, Version) bool { return true }, nil
+	case "dead":
+		return func(name string, _ Version) bool {
+			for _, d := range deadBrowsers {
+				if name == d {
+					return true
+				}
+			}
+			return false
+		}, nil
+	case "firefox esr", "ff esr", "fx esr":
+		min := ParseVersion(firefoxESR[len(firefoxESR)-2])
+		return func(name string, v Version) bool {
+			if name != "Firefox" || v.IsZero() || v.Less(min) {
+				return false
+			}
+			for _, esr := range firefoxESR {
+				if strconv.Itoa(v.Major) == esr {
+					return true
+				}
+			}
+			return false
+		}, nil
+	case "defaults":
+		return nil, fmt.Errorf("%q is not supported as it needs usage statistics", t)
+	}
+	if strings.ContainsAny(t, "%") {
+		return nil, fmt.Errorf("%q is not supported as it needs usage statistics", t)
+	}
+
+	if m := reLast.FindStringSubmatch(t); m != nil {
+		n, _ := strconv.Atoi(m[1])
+		if n < 1 {
+			return nil, fmt.Errorf("%q: need at least one version", t)
+		}
+		min := make(map[string]Version)
+		if m[2] != "" {
+			name, err := policyBrowser(m[2])
+			if err != nil {
+				return nil, err
+			}
+			min[name] = lastVersion(name, n)
+		} else {
+			for name := range browserReleases {
+				min[name] = lastVersion(name, n)
+			}
+		}
+		return func(name string, v Version) bool {
+			m, ok := min[name]
+			return ok && !v.IsZero() && !v.Less(m)
+		}, nil
+	}
+	if m := reLastYears.FindStringSubmatch(t); m != nil {
+		years, _ := strconv.ParseFloat(m[1], 64)
+		return releasedSince(time.Now().Add(-time.Duration(years * 365.25 * 24 * float64(time.Hour)))), nil
+	}
+	if m := reSince.FindStringSubmatch(t); m != nil {
+		layout := "2006-01-02"[:len(m[1])]
+		since, err := time.Parse(layout, m[1])
+		if err != nil {
+			return nil, fmt.Errorf("%q: %w", t, err)
+		}
+		return releasedSince(since), nil
+	}
+
+	if m := reCompare.FindStringSubmatch(t); m != nil {
+		name, err := policyBrowser(m[1])
+		if err != nil {
+			return nil, err
+		}
+		cmp := ParseVersion(m[3])
+		op := m[2]
+		return func(n string, v Version) bool {
+			if n != name || v.IsZero() {
+				return false
+			}
+			c := v.Compare(cmp)
+			switch op {
+			case ">":
+				return c > 0
+			case ">=":
+				return c >= 0
+			case "<":
+				return c < 0
+			default:
+				return c <= 0
+			}
+		}, nil
+	}
+	if m := reRange.FindStringSubmatch(t); m != nil {
+		name, err := policyBrowser(m[1])
+		if err != nil {
+			return nil, err
+		}
+		from, to := ParseVersion(m[2]), ParseVersion(m[3])
+		return func(n string, v Version) bool {
+			return n == name && !v.IsZero() && !v.Less(from) && !to.Less(truncVersion(v, m[3]))
+		}, nil
+	}
+	if m := reExact.FindStringSubmatch(t); m != nil {
+		name, err := policyBrowser(m[1])
+		if err != nil {
+			return nil, err
+		}
+		want := ParseVersion(m[2])
+		return func(n string, v Version) bool {
+			return n == name && !v.IsZero() && truncVersion(v, m[2]) == want
+		}, nil
+	}
+
+	return nil, fmt.Errorf("unknown query %q", t)
+}
+
+func policyBrowser(name string) (string, error) {
+	b, ok := policyBrowsers[strings.ToLower(name)]
+	if !ok {
+		return "", fmt.Errorf("unknown browser %q", name)
+	}
+	return b, nil
+}
+
+// Get the n-th latest version of a browser.
+func lastVersion(name string, n int) Version {
+	rel := browserReleases[name]
+	if len(rel) == 0 {
+		return Version{}
+	}
+	if n > len(rel) {
+		n = len(rel)
+	}
+	return ParseVersion(rel[len(rel)-n].version)
+}
+
+// Match all versions released since the given date; this includes versions
+// newer than the latest one in browserReleases, even if the date is after the
+// latest release.
+func releasedSince(since time.Time) func(string, Version) bool {
+	return func(name string, v Version) bool {
+		rel := browserReleases[name]
+		if len(rel) == 0 || v.IsZero() {
+			return false
+		}
+		for _, r := range rel {
+			if !r.released.Before(since) {
+				return !v.Less(ParseVersion(r.version))
+			}
+		}
+		return ParseVersion(rel[len(rel)-1].version).Less(v)
+	}
+}
+
+// Truncate the version to the same precision as the query, so that "Safari 14"
+// matches "14.1".
+func truncVersion(v Version, query string) Version {
+	switch strings.Count(query, ".") {
+	case 0:
+		return Version{Major: v.Major}
+	case 1:
+		return Version{Major: v.Major, Minor: v.Minor}
+	default:
+		return v
+	}
+}
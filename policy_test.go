@@ -0,0 +1,118 @@
+package gadget
+
+import (
+	"fmt"
+	"testing"
+)
+
+func TestPolicy(t *testing.T) {
+	var (
+		chrome = func(v string) UserAgent { return UserAgent{BrowserName: "Chrome", BrowserVersion: v} }
+		ff     = func(v string) UserAgent { return UserAgent{BrowserName: "Firefox", BrowserVersion: v} }
+		safari = func(v string) UserAgent { return UserAgent{BrowserName: "Safari", BrowserVersion: v} }
+		ie     = func(v string) UserAgent { return UserAgent{BrowserName: "Internet Explorer", BrowserVersion: v} }
+	)
+
+	tests := []struct {
+		query string
+		in    UserAgent
+		want  bool
+	}{
+		{"last 2 Chrome versions", chrome("142"), true},
+		{"last 2 Chrome versions", chrome("141"), true},
+		{"last 2 Chrome versions", chrome("150"), true},
+		{"last 2 Chrome versions", chrome("140"), false},
+		{"last 2 Chrome versions", ff("144"), false},
+		{"last 2 chrome major versions", chrome("141"), true},
+		{"last 2 versions", ff("143"), true},
+		{"last 2 versions", ff("142"), false},
+		{"last 2 major versions", safari("18.4"), true},
+		{"last 2 versions", ie("10"), true},
+		{"last 2 versions, not dead", ie("10"), false},
+		{"last 2 versions, not dead", chrome("142"), true},
+
+		{"Firefox ESR", ff("140"), true},
+		{"Firefox ESR", ff("128"), true},
+		{"Firefox ESR", ff("115"), false},
+		{"Firefox ESR", ff("139"), false},
+
+		{"Safari >= 14", safari("14.1"), true},
+		{"Safari >= 14", safari("13.1"), false},
+		{"Safari > 14", safari("14.1"), true},
+		{"Safari < 14", safari("13.1"), true},
+		{"Safari <= 14", safari("14"), true},
+		{"Safari 14", safari("14.1"), true},
+		{"Safari 14.1", safari("14"), false},
+		{"Safari 13-14", safari("14.1"), true},
+		{"Safari 13-14", safari("15"), false},
+
+		{"since 2025", chrome("132"), true},
+		{"since 2025-01", chrome("131"), false},
+		{"since 2025-01-14", chrome("132"), true},
+		{"since 2025", ie("11"), false},
+		{"since 2030", chrome("150"), true},
+		{"since 2030", chrome("142"), false},
+		{"since 2030", safari("26.1"), true},
+		{"since 2030", ie("11"), false},
+		{"last 1 years", ff("150"), true},
+		{"last 1 years", safari("26.1"), true},
+		{"last 1 years", chrome("90"), false},
+
+		{"Safari < 14", safari(""), false},
+		{"Safari 13-14", safari(""), false},
+		{"Safari 0", safari(""), false},
+		{"last 2 versions", safari(""), false},
+		{"since 2025", safari(""), false},
+		{"Firefox ESR", ff(""), false},
+		{"all", safari(""), true},
+		{"Safari < 14, not dead", safari(""), false},
+
+		{"dead", ie("11"), true},
+		{"all", ie("11"), true},
+		{"all", UserAgent{}, false},
+		{"Chrome > 100 and Chrome < 110", chrome("105"), true},
+		{"Chrome > 100 and Chrome < 110", chrome("115"), false},
+		{"Chrome > 100 or Firefox > 100", ff("115"), true},
+
+		{"last 2 Chrome versions, Firefox ESR, Safari >= 14, not dead", safari("17"), true},
+		{"last 2 Chrome versions, Firefox ESR, Safari >= 14, not dead", chrome("90"), false},
+		{"last 2 Chrome versions, Firefox ESR, Safari >= 14, not dead", UserAgent{BrowserName: "curl", BrowserVersion: "7.0"}, false},
+	}
+
+	for i, tt := range tests {
+		t.Run(fmt.Sprintf("%d", i), func(t *testing.T) {
+			p, err := CompilePolicy(tt.query)
+			if err != nil {
+				t.Fatal(err)
+			}
+			got := p.Allows(tt.in)
+			if got != tt.want {
+				t.Errorf("%q allows %q\ngot:  %t\nwant: %t", tt.query, tt.in, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestCompilePolicyError(t *testing.T) {
+	tests := []struct {
+		query, wantErr string
+	}{
+		{"", "empty query"},
+		{"not dead", `can't start with "not"`},
+		{"Chrome > 80,", "empty term"},
+		{"defaults", "usage statistics"},
+		{"> 0.5%", "usage statistics"},
+		{"last 2 netscape versions", `unknown browser "netscape"`},
+		{"last 0 versions", "at least one"},
+		{"maintained node versions", "unknown query"},
+	}
+
+	for i, tt := range tests {
+		t.Run(fmt.Sprintf("%d", i), func(t *testing.T) {
+			_, err := CompilePolicy(tt.query)
+			if !errorContains(err, tt.wantErr) {
+				t.Errorf("\ngot:  %v\nwant: %v", err, tt.wantErr)
+			}
+		})
+	}
+}
@@ -0,0 +1,153 @@
+package gadget
+
+import "strings"
+
+// What a product is used for; a product can match more than one of these, for
+// example "Mobile/15E148" is both a Safari trigger and ignored as a browser.
+const (
+	prodKnown    = 1 << iota // knownBrowsers
+	prodChrome               // Chrome/
+	prodChromium             // Chromium/
+	prodHeadless             // HeadlessChrome/
+	prodFirefox              // Firefox/
+	prodOpera                // Opera/
+	prodEdge                 // Edge/ (EdgeHTML, not the Chromium-based Edg/)
+	prodSafari               // Safari/ or Mobile/15E148
+	prodIOS                  // Products that indicate Safari on iOS.
+	prodVersion              // Version/
+	prodWebKit               // AppleWebKit/
+	prodKaiOS                // KAIOS/
+	prodDistro               // Linux distributions.
+	prodIgnore               // ignoreProduct
+
+	prodBrowser = prodChrome | prodChromium | prodHeadless | prodFirefox | prodOpera
+)
+
+type productRule struct {
+	prefix string
+	exact  bool
+	kind   int
+}
+
+// productRules are all the products ParseUA() looks at; knownBrowsers and
+// ignoreProduct are added in init().
+var productRules = []productRule{
+	{prefix: "Chrome/", kind: prodChrome},
+	{prefix: "Chromium/", kind: prodChromium},
+	{prefix: "HeadlessChrome/", kind: prodHeadless},
+	{prefix: "Firefox/", kind: prodFirefox},
+	{prefix: "Opera/", kind: prodOpera},
+	{prefix: "Edge/", kind: prodEdge},
+	{prefix: "Safari/", kind: prodSafari},
+	{prefix: "Mobile/15E148", exact: true, kind: prodSafari},
+	{prefix: "FxiOS/", kind: prodIOS},
+	{prefix: "CriOS/", kind: prodIOS},
+	{prefix: "Mobile/", kind: prodIOS},
+	{prefix: "Version/", kind: prodVersion},
+	{prefix: "AppleWebKit/", kind: prodWebKit},
+	{prefix: "KAIOS/", kind: prodKaiOS},
+	{prefix: "Ubuntu", exact: true, kind: prodDistro},
+	{prefix: "CentOS", exact: true, kind: prodDistro},
+	{prefix: "Fedora", exact: true, kind: prodDistro},
+	{prefix: "Debian", exact: true, kind: prodDistro},
+}
+
+// productRules indexed by the first byte, so that matching a product only
+// looks at the handful of rules that can possibly match.
+var productIndex [256][]productRule
+
+func init() {
+	rules := append([]productRule{}, productRules...)
+	for _, k := range knownBrowsers {
+		rules = append(rules, productRule{prefix: k, kind: prodKnown})
+	}
+	for _, ig := range ignoreProduct {
+		rules = append(rules, productRule{prefix: ig, kind: prodIgnore})
+	}
+	for _, r := range rules {
+		productIndex[r.prefix[0]] = append(productIndex[r.prefix[0]], r)
+	}
+}
+
+// matchProduct gets all the prod* kinds for a product.
+func matchProduct(s string) int {
+	if s == "" {
+		return 0
+	}
+	kind := 0
+	for _, r := range productIndex[s[0]] {
+		if r.exact && s == r.prefix || !r.exact && strings.HasPrefix(s, r.prefix) {
+			kind |= r.kind
+		}
+	}
+	return kind
+}
+
+// productScan is the result of scanning all products once; the indexes are
+// into props.products, and are -1 if there is no such product.
+type productScan struct {
+	first   int // Kind of the first product.
+	known   int // First of knownBrowsers.
+	browser int // First Chrome, Chromium, HeadlessChrome, Firefox, or Opera.
+	kind    int // Kind of the browser product.
+	safari  int // First Safari trigger.
+	edge    int // First Edge/
+	kaios   int // First KAIOS/
+	distro  int // First Linux distribution.
+
+	version       int // Last Version/
+	safariVersion int // Last Version/ without 15E (e.g. "Version/15E148").
+	webkit        int // Last AppleWebKit/
+
+	ios    bool // Has FxiOS/, CriOS/, or Mobile/
+	frozen bool // Has a Chrome/ version ending in .0.0.0
+}
+
+func scanProducts(products []string) productScan {
+	m := productScan{known: -1, browser: -1, safari: -1, edge: -1, kaios: -1,
+		distro: -1, version: -1, safariVersion: -1, webkit: -1}
+	for i, s := range products {
+		kind := matchProduct(s)
+		if i == 0 {
+			m.first = kind
+		}
+		if kind == 0 {
+			continue
+		}
+
+		if kind&prodKnown != 0 && m.known == -1 {
+			m.known = i
+		}
+		if kind&prodBrowser != 0 && m.browser == -1 {
+			m.browser, m.kind = i, kind
+		}
+		if kind&prodSafari != 0 && m.safari == -1 {
+			m.safari = i
+		}
+		if kind&prodEdge != 0 && m.edge == -1 {
+			m.edge = i
+		}
+		if kind&prodKaiOS != 0 && m.kaios == -1 {
+			m.kaios = i
+		}
+		if kind&prodDistro != 0 && m.distro == -1 {
+			m.distro = i
+		}
+		if kind&prodVersion != 0 {
+			m.version = i
+			if !strings.Contains(s, "15E") {
+				m.safariVersion = i
+			}
+		}
+		if kind&prodWebKit != 0 {
+			m.webkit = i
+		}
+		if kind&prodIOS != 0 {
+			m.ios = true
+		}
+		if kind&prodChrome != 0 && strings.HasSuffix(s, ".0.0.0") {
+			m.frozen = true
+		}
+	}
+	return m
+}
@@ -0,0 +1,35 @@
+package gadget
+
+import (
+	"fmt"
+	"testing"
+)
+
+func TestMatchProduct(t *testing.T) {
+	tests := []struct {
+		in   string
+		want int
+	}{
+		{"", 0},
+		{"X", 0},
+		{"Chrome/80.0", prodChrome},
+		{"Chromium/80.0", prodChromium},
+		{"Mobile/15E148", prodSafari | prodIOS | prodIgnore},
+		{"Mobile/15E1480", prodIOS | prodIgnore},
+		{"Version/14.0", prodVersion | prodIgnore},
+		{"AppleWebKit/605.1.15", prodWebKit | prodIgnore},
+		{"Dillo/3.0", prodKnown},
+		{"Ubuntu", prodDistro},
+		{"Ubuntu/20.04", 0},
+		{"likewise", prodIgnore},
+	}
+
+	for i, tt := range tests {
+		t.Run(fmt.Sprintf("%d", i), func(t *testing.T) {
+			got := matchProduct(tt.in)
+			if got != tt.want {
+				t.Errorf("\ngot:  %b\nwant: %b", got, tt.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,334 @@
+package gadget
+
+import (
+	"strings"
+	"time"
+)
+
+// release is a single browser or OS version.
+type release struct {
+	version  string
+	released time.Time
+}
+
+// Major browser releases, oldest first, keyed by the BrowserName ParseUA()
+// uses.
+//
+// Edge only lists the EdgeHTML versions, as the Chromium-based Edge is reported
+// as Chrome, and Opera only lists the Presto versions for the same reason.
+var browserReleases = map[string][]release{
+	"Chrome": {
+		{"1", day(2008, 12, 11)}, {"2", day(2009, 5, 24)}, {"3", day(2009, 10, 12)},
+		{"4", day(2010, 1, 25)}, {"5", day(2010, 5, 25)}, {"6", day(2010, 9, 2)},
+		{"7", day(2010, 10, 21)}, {"8", day(2010, 12, 2)}, {"9", day(2011, 2, 3)},
+		{"10", day(2011, 3, 8)}, {"11", day(2011, 4, 27)}, {"12", day(2011, 6, 7)},
+		{"13", day(2011, 8, 2)}, {"14", day(2011, 9, 16)}, {"15", day(2011, 10, 25)},
+		{"16", day(2011, 12, 13)}, {"17", day(2012, 2, 8)}, {"18", day(2012, 3, 28)},
+		{"19", day(2012, 5, 15)}, {"20", day(2012, 6, 26)}, {"21", day(2012, 7, 31)},
+		{"22", day(2012, 9, 25)}, {"23", day(2012, 11, 6)}, {"24", day(2013, 1, 10)},
+		{"25", day(2013, 2, 21)}, {"26", day(2013, 3, 26)}, {"27", day(2013, 5, 21)},
+		{"28", day(2013, 7, 9)}, {"29", day(2013, 8, 20)}, {"30", day(2013, 10, 1)},
+		{"31", day(2013, 11, 12)}, {"32", day(2014, 1, 14)}, {"33", day(2014, 2, 20)},
+		{"34", day(2014, 4, 8)}, {"35", day(2014, 5, 20)}, {"36", day(2014, 7, 16)},
+		{"37", day(2014, 8, 26)}, {"38", day(2014, 10, 7)}, {"39", day(2014, 11, 18)},
+		{"40", day(2015, 1, 21)}, {"41", day(2015, 3, 3)}, {"42", day(2015, 4, 14)},
+		{"43", day(2015, 5, 19)}, {"44", day(2015, 7, 21)}, {"45", day(2015, 9, 1)},
+		{"46", day(2015, 10, 13)}, {"47", day(2015, 12, 1)}, {"48", day(2016, 1, 20)},
+		{"49", day(2016, 3, 2)}, {"50", day(2016, 4, 13)}, {"51", day(2016, 5, 25)},
+		{"52", day(2016, 7, 20)}, {"53", day(2016, 8, 31)}, {"54", day(2016, 10, 12)},
+		{"55", day(2016, 12, 1)}, {"56", day(2017, 1, 25)}, {"57", day(2017, 3, 9)},
+		{"58", day(2017, 4, 19)}, {"59", day(2017, 6, 5)}, {"60", day(2017, 7, 25)},
+		{"61", day(2017, 9, 5)}, {"62", day(2017, 10, 17)}, {"63", day(2017, 12, 6)},
+		{"64", day(2018, 1, 24)}, {"65", day(2018, 3, 6)}, {"66", day(2018, 4, 17)},
+		{"67", day(2018, 5, 29)}, {"68", day(2018, 7, 24)}, {"69", day(2018, 9, 4)},
+		{"70", day(2018, 10, 16)}, {"71", day(2018, 12, 4)}, {"72", day(2019, 1, 29)},
+		{"73", day(2019, 3, 12)}, {"74", day(2019, 4, 23)}, {"75", day(2019, 6, 4)},
+		{"76", day(2019, 7, 30)}, {"77", day(2019, 9, 10)}, {"78", day(2019, 10, 22)},
+		{"79", day(2019, 12, 10)}, {"80", day(2020, 2, 4)}, {"81", day(2020, 4, 7)},
+		// There was no Chrome 82.
+		{"83", day(2020, 5, 19)}, {"84", day(2020, 7, 14)}, {"85", day(2020, 8, 25)},
+		{"86", day(2020, 10, 6)}, {"87", day(2020, 11, 17)}, {"88", day(2021, 1, 19)},
+		{"89", day(2021, 3, 2)}, {"90", day(2021, 4, 14)}, {"91", day(2021, 5, 25)},
+		{"92", day(2021, 7, 20)}, {"93", day(2021, 8, 31)}, {"94", day(2021, 9, 21)},
+		{"95", day(2021, 10, 19)}, {"96", day(2021, 11, 15)}, {"97", day(2022, 1, 4)},
+		{"98", day(2022, 2, 1)}, {"99", day(2022, 3, 1)}, {"100", day(2022, 3, 29)},
+		{"101", day(2022, 4, 26)}, {"102", day(2022, 5, 24)}, {"103", day(2022, 6, 21)},
+		{"104", day(2022, 8, 2)}, {"105", day(2022, 8, 30)}, {"106", day(2022, 9, 27)},
+		{"107", day(2022, 10, 25)}, {"108", day(2022, 11, 29)}, {"109", day(2023, 1, 10)},
+		{"110", day(2023, 2, 7)}, {"111", day(2023, 3, 7)}, {"112", day(2023, 4, 4)},
+		{"113", day(2023, 5, 2)}, {"114", day(2023, 5, 30)}, {"115", day(2023, 7, 18)},
+		{"116", day(2023, 8, 15)}, {"117", day(2023, 9, 12)}, {"118", day(2023, 10, 10)},
+		{"119", day(2023, 10, 31)}, {"120", day(2023, 12, 5)}, {"121", day(2024, 1, 23)},
+		{"122", day(2024, 2, 20)}, {"123", day(2024, 3, 19)}, {"124", day(2024, 4, 16)},
+		{"125", day(2024, 5, 14)}, {"126", day(2024, 6, 11)}, {"127", day(2024, 7, 23)},
+		{"128", day(2024, 8, 20)}, {"129", day(2024, 9, 17)}, {"130", day(2024, 10, 15)},
+		{"131", day(2024, 11, 12)}, {"132", day(2025, 1, 14)}, {"133", day(2025, 2, 4)},
+		{"134", day(2025, 3, 4)}, {"135", day(2025, 4, 1)}, {"136", day(2025, 4, 29)},
+		{"137", day(2025, 5, 27)}, {"138", day(2025, 6, 24)}, {"139", day(2025, 8, 5)},
+		{"140", day(2025, 9, 2)}, {"141", day(2025, 9, 30)}, {"142", day(2025, 10, 28)},
+	},
+	"Firefox": {
+		{"1", day(2004, 11, 9)}, {"2", day(2006, 10, 24)}, {"3", day(2008, 6, 17)},
+		{"4", day(2011, 3, 22)}, {"5", day(2011, 6, 21)}, {"6", day(2011, 8, 16)},
+		{"7", day(2011, 9, 27)}, {"8", day(2011, 11, 8)}, {"9", day(2011, 12, 20)},
+		{"10", day(2012, 1, 31)}, {"11", day(2012, 3, 13)}, {"12", day(2012, 4, 24)},
+		{"13", day(2012, 6, 5)}, {"14", day(2012, 7, 17)}, {"15", day(2012, 8, 28)},
+		{"16", day(2012, 10, 9)}, {"17", day(2012, 11, 20)}, {"18", day(2013, 1, 8)},
+		{"19", day(2013, 2, 19)}, {"20", day(2013, 4, 2)}, {"21", day(2013, 5, 14)},
+		{"22", day(2013, 6, 25)}, {"23", day(2013, 8, 6)}, {"24", day(2013, 9, 17)},
+		{"25", day(2013, 10, 29)}, {"26", day(2013, 12, 10)}, {"27", day(2014, 2, 4)},
+		{"28", day(2014, 3, 18)}, {"29", day(2014, 4, 29)}, {"30", day(2014, 6, 10)},
+		{"31", day(2014, 7, 22)}, {"32", day(2014, 9, 2)}, {"33", day(2014, 10, 14)},
+		{"34", day(2014, 12, 1)}, {"35", day(2015, 1, 13)}, {"36", day(2015, 2, 24)},
+		{"37", day(2015, 3, 31)}, {"38", day(2015, 5, 12)}, {"39", day(2015, 7, 2)},
+		{"40", day(2015, 8, 11)}, {"41", day(2015, 9, 22)}, {"42", day(2015, 11, 3)},
+		{"43", day(2015, 12, 15)}, {"44", day(2016, 1, 26)}, {"45", day(2016, 3, 8)},
+		{"46", day(2016, 4, 26)}, {"47", day(2016, 6, 7)}, {"48", day(2016, 8, 2)},
+		{"49", day(2016, 9, 20)}, {"50", day(2016, 11, 15)}, {"51", day(2017, 1, 24)},
+		{"52", day(2017, 3, 7)}, {"53", day(2017, 4, 19)}, {"54", day(2017, 6, 13)},
+		{"55", day(2017, 8, 8)}, {"56", day(2017, 9, 28)}, {"57", day(2017, 11, 14)},
+		{"58", day(2018, 1, 23)}, {"59", day(2018, 3, 13)}, {"60", day(2018, 5, 9)},
+		{"61", day(2018, 6, 26)}, {"62", day(2018, 9, 5)}, {"63", day(2018, 10, 23)},
+		{"64", day(2018, 12, 11)}, {"65", day(2019, 1, 29)}, {"66", day(2019, 3, 19)},
+		{"67", day(2019, 5, 21)}, {"68", day(2019, 7, 9)}, {"69", day(2019, 9, 3)},
+		{"70", day(2019, 10, 22)}, {"71", day(2019, 12, 3)}, {"72", day(2020, 1, 7)},
+		{"73", day(2020, 2, 11)}, {"74", day(2020, 3, 10)}, {"75", day(2020, 4, 7)},
+		{"76", day(2020, 5, 5)}, {"77", day(2020, 6, 2)}, {"78", day(2020, 6, 30)},
+		{"79", day(2020, 7, 28)}, {"80", day(2020, 8, 25)}, {"81", day(2020, 9, 22)},
+		{"82", day(2020, 10, 20)}, {"83", day(2020, 11, 17)}, {"84", day(2020, 12, 15)},
+		{"85", day(2021, 1, 26)}, {"86", day(2021, 2, 23)}, {"87", day(2021, 3, 23)},
+		{"88", day(2021, 4, 19)}, {"89", day(2021, 6, 1)}, {"90", day(2021, 7, 13)},
+		{"91", day(2021, 8, 10)}, {"92", day(2021, 9, 7)}, {"93", day(2021, 10, 5)},
+		{"94", day(2021, 11, 2)}, {"95", day(2021, 12, 7)}, {"96", day(2022, 1, 11)},
+		{"97", day(2022, 2, 8)}, {"98", day(2022, 3, 8)}, {"99", day(2022, 4, 5)},
+		{"100", day(2022, 5, 3)}, {"101", day(2022, 5, 31)}, {"102", day(2022, 6, 28)},
+		{"103", day(2022, 7, 26)}, {"104", day(2022, 8, 23)}, {"105", day(2022, 9, 20)},
+		{"106", day(2022, 10, 18)}, {"107", day(2022, 11, 15)}, {"108", day(2022, 12, 13)},
+		{"109", day(2023, 1, 17)}, {"110", day(2023, 2, 14)}, {"111", day(2023, 3, 14)},
+		{"112", day(2023, 4, 11)}, {"113", day(2023, 5, 9)}, {"114", day(2023, 6, 6)},
+		{"115", day(2023, 7, 4)}, {"116", day(2023, 8, 1)}, {"117", day(2023, 8, 29)},
+		{"118", day(2023, 9, 26)}, {"119", day(2023, 10, 24)}, {"120", day(2023, 11, 21)},
+		{"121", day(2023, 12, 19)}, {"122", day(2024, 1, 23)}, {"123", day(2024, 2, 20)},
+		{"124", day(2024, 3, 19)}, {"125", day(2024, 4, 16)}, {"126", day(2024, 5, 14)},
+		{"127", day(2024, 6, 11)}, {"128", day(2024, 7, 9)}, {"129", day(2024, 8, 6)},
+		{"130", day(2024, 9, 3)}, {"131", day(2024, 10, 1)}, {"132", day(2024, 10, 29)},
+		{"133", day(2024, 11, 26)}, {"134", day(2025, 1, 7)}, {"135", day(2025, 2, 4)},
+		{"136", day(2025, 3, 4)}, {"137", day(2025, 4, 1)}, {"138", day(2025, 4, 29)},
+		{"139", day(2025, 5, 27)}, {"140", day(2025, 6, 24)}, {"141", day(2025, 7, 22)},
+		{"142", day(2025, 8, 19)}, {"143", day(2025, 9, 16)}, {"144", day(2025, 10, 14)},
+	},
+	"Safari": {
+		{"1", day(2003, 6, 23)}, {"2", day(2005, 4, 29)}, {"3", day(2007, 6, 11)},
+		{"4", day(2009, 6, 8)}, {"5", day(2010, 6, 7)}, {"6", day(2012, 7, 25)},
+		{"7", day(2013, 10, 22)}, {"8", day(2014, 10, 16)}, {"9", day(2015, 9, 30)},
+		{"10", day(2016, 9, 20)}, {"11", day(2017, 9, 19)}, {"12", day(2018, 9, 17)},
+		{"13", day(2019, 9, 19)}, {"14", day(2020, 9, 16)}, {"15", day(2021, 9, 20)},
+		{"16", day(2022, 9, 12)}, {"17", day(2023, 9, 18)}, {"18", day(2024, 9, 16)},
+		// Versions 19 to 25 were skipped to match the OS versions.
+		{"26", day(2025, 9, 15)},
+	},
+	"Edge": {
+		{"12", day(2015, 7, 29)}, {"13", day(2015, 11, 12)}, {"14", day(2016, 8, 2)},
+		{"15", day(2017, 4, 5)}, {"16", day(2017, 10, 17)}, {"17", day(2018, 4, 30)},
+		{"18", day(2018, 11, 13)},
+	},
+	"Internet Explorer": {
+		{"6", day(2001, 8, 27)}, {"7", day(2006, 10, 18)}, {"8", day(2009, 3, 19)},
+		{"9", day(2011, 3, 14)}, {"10", day(2012, 10, 26)}, {"11", day(2013, 10, 17)},
+	},
+	"Opera": {
+		{"9", day(2006, 6, 20)}, {"10", day(2009, 9, 1)}, {"11", day(2010, 12, 16)},
+		{"12", day(2012, 6, 14)},
+	},
+}
+
+// osRelease is a single OS version.
+type osRelease struct {
+	version  string
+	released time.Time
+	eol      time.Time
+}
+
+// OS releases, oldest first, keyed by the OSName and OSVersion ParseUA() uses.
+//
+// Only Windows has documented end-of-life dates; for the others it's
+// approximated with osSupported.
+var osReleases = map[string][]osRelease{
+	"Windows": {
+		{"2000", day(2000, 2, 17), day(2010, 7, 13)},
+		{"XP", day(2001, 10, 25), day(2014, 4, 8)},
+		{"Vista", day(2007, 1, 30), day(2017, 4, 11)},
+		{"7", day(2009, 10, 22), day(2020, 1, 14)},
+		{"8", day(2012, 10, 26), day(2016, 1, 12)},
+		{"8.1", day(2013, 10, 17), day(2023, 1, 10)},
+		{"10", day(2015, 7, 29), day(2025, 10, 14)},
+		{"11", day(2021, 10, 5), time.Time{}},
+	},
+	"macOS": {
+		{"10.6", day(2009, 8, 28), time.Time{}},
+		{"10.7", day(2011, 7, 20), time.Time{}},
+		{"10.8", day(2012, 7, 25), time.Time{}},
+		{"10.9", day(2013, 10, 22), time.Time{}},
+		{"10.10", day(2014, 10, 16), time.Time{}},
+		{"10.11", day(2015, 9, 30), time.Time{}},
+		{"10.12", day(2016, 9, 20), time.Time{}},
+		{"10.13", day(2017, 9, 25), time.Time{}},
+		{"10.14", day(2018, 9, 24), time.Time{}},
+		{"10.15", day(2019, 10, 7), time.Time{}},
+		{"11", day(2020, 11, 12), time.Time{}},
+		{"12", day(2021, 10, 25), time.Time{}},
+		{"13", day(2022, 10, 24), time.Time{}},
+		{"14", day(2023, 9, 26), time.Time{}},
+		{"15", day(2024, 9, 16), time.Time{}},
+		{"26", day(2025, 9, 15), time.Time{}},
+	},
+	"iOS": {
+		{"7", day(2013, 9, 18), time.Time{}},
+		{"8", day(2014, 9, 17), time.Time{}},
+		{"9", day(2015, 9, 16), time.Time{}},
+		{"10", day(2016, 9, 13), time.Time{}},
+		{"11", day(2017, 9, 19), time.Time{}},
+		{"12", day(2018, 9, 17), time.Time{}},
+		{"13", day(2019, 9, 19), time.Time{}},
+		{"14", day(2020, 9, 16), time.Time{}},
+		{"15", day(2021, 9, 20), time.Time{}},
+		{"16", day(2022, 9, 12), time.Time{}},
+		{"17", day(2023, 9, 18), time.Time{}},
+		{"18", day(2024, 9, 16), time.Time{}},
+		{"26", day(2025, 9, 15), time.Time{}},
+	},
+	"Android": {
+		{"4.4", day(2013, 10, 31), time.Time{}},
+		{"5", day(2014, 11, 12), time.Time{}},
+		{"5.1", day(2015, 3, 9), time.Time{}},
+		{"6", day(2015, 10, 5), time.Time{}},
+		{"7", day(2016, 8, 22), time.Time{}},
+		{"7.1", day(2016, 10, 4), time.Time{}},
+		{"8", day(2017, 8, 21), time.Time{}},
+		{"8.1", day(2017, 12, 5), time.Time{}},
+		{"9", day(2018, 8, 6), time.Time{}},
+		{"10", day(2019, 9, 3), time.Time{}},
+		{"11", day(2020, 9, 8), time.Time{}},
+		{"12", day(2021, 10, 4), time.Time{}},
+		{"13", day(2022, 8, 15), time.Time{}},
+		{"14", day(2023, 10, 4), time.Time{}},
+		{"15", day(2024, 10, 15), time.Time{}},
+		{"16", day(2025, 6, 10), time.Time{}},
+	},
+}
+
+// Number of major versions that get security updates, for systems without a
+// documented end-of-life date. A version is considered end-of-life once this
+// many newer major versions are released.
+var osSupported = map[string]int{
+	"macOS":   3,
+	"iOS":     2,
+	"Android": 4,
+}
+
+// Firefox Extended Support Releases, oldest first.
+var firefoxESR = []string{"60", "68", "78", "91", "102", "115", "128", "140"}
+
+// Browsers that haven't been updated in more than two years.
+var deadBrowsers = []string{"Edge", "Internet Explorer", "Opera"}
+
+func day(y, m, d int) time.Time { return time.Date(y, time.Month(m), d, 0, 0, 0, 0, time.UTC) }
+
+// BrowserReleased gets the release date of the browser's major version.
+//
+// This is the zero time if it's not known.
+func (u UserAgent) BrowserReleased() time.Time {
+	rel := browserReleases[u.BrowserName]
+	i := findRelease(len(rel), func(i int) string { return rel[i].version }, u.BrowserVersion)
+	if i == -1 {
+		return time.Time{}
+	}
+	return rel[i].released
+}
+
+// OSReleased gets the release date of the OS version.
+//
+// This is the zero time if it's not known or if this is a reduced User-Agent.
+func (u UserAgent) OSReleased() time.Time {
+	rel, i := u.osRelease()
+	if i == -1 {
+		return time.Time{}
+	}
+	return rel[i].released
+}
+
+// OSEndOfLife gets the date support for the OS version ended, or will end.
+//
+// This returns false if there is no end-of-life date because it's still
+// supported, if the OS version isn't known, or if this is a reduced User-Agent.
+//
+// Browsers report Windows 11 as "Windows NT 10.0", and Safari, Firefox, and
+// Chrome report every macOS version since 11 as "Mac OS X 10_15_7" or "Mac OS
+// X 10.15". The dates for Windows 10 and macOS 10.15 are still returned, but
+// the actual version may be newer if OSFromHints is false.
+//
+// Only Windows has documented end-of-life dates; for macOS, iOS, and Android
+// it's approximated as the release of the third (macOS), second (iOS), or
+// fourth (Android) newer major version.
+func (u UserAgent) OSEndOfLife() (time.Time, bool) {
+	rel, i := u.osRelease()
+	if i == -1 {
+		return time.Time{}, false
+	}
+	if !rel[i].eol.IsZero() {
+		return rel[i].eol, true
+	}
+
+	n, ok := osSupported[u.OSName]
+	if !ok {
+		return time.Time{}, false
+	}
+	major := osMajor(u.OSName, rel[i].version)
+	for _, r := range rel[i+1:] {
+		if m := osMajor(u.OSName, r.version); m != major {
+			major = m
+			n--
+			if n == 0 {
+				return r.released, true
+			}
+		}
+	}
+	return time.Time{}, false
+}
+
+func (u UserAgent) osRelease() ([]osRelease, int) {
+	if u.Reduced {
+		return nil, -1
+	}
+	rel := osReleases[u.OSName]
+	return rel, findRelease(len(rel), func(i int) string { return rel[i].version }, u.OSVersion)
+}
+
+// Find the index of the release for version v, removing the last part of the
+// version until there's a match so that "14.1" matches the "14" release.
+func findRelease(n int, version func(int) string, v string) int {
+	for v != "" {
+		for i := 0; i < n; i++ {
+			if version(i) == v {
+				return i
+			}
+		}
+		j := strings.LastIndexByte(v, '.')
+		if j == -1 {
+			break
+		}
+		v = v[:j]
+	}
+	return -1
+}
+
+func osMajor(name, v string) string {
+	// Every macOS 10.x was a major release.
+	if name == "macOS" && strings.HasPrefix(v, "10.") {
+		return v
+	}
+	if i := strings.IndexByte(v, '.'); i > -1 {
+		return v[:i]
+	}
+	return v
+}
@@ -0,0 +1,76 @@
+package gadget
+
+import (
+	"fmt"
+	"testing"
+	"time"
+)
+
+func TestBrowserReleased(t *testing.T) {
+	tests := []struct {
+		in   UserAgent
+		want string
+	}{
+		{UserAgent{}, ""},
+		{UserAgent{BrowserName: "Chrome", BrowserVersion: "80"}, "2020-02-04"},
+		{UserAgent{BrowserName: "Safari", BrowserVersion: "14.1"}, "2020-09-16"},
+		{UserAgent{BrowserName: "Firefox", BrowserVersion: "3"}, "2008-06-17"},
+		{UserAgent{BrowserName: "Chrome", BrowserVersion: "999"}, ""},
+		{UserAgent{BrowserName: "Dillo", BrowserVersion: "3.0"}, ""},
+	}
+
+	for i, tt := range tests {
+		t.Run(fmt.Sprintf("%d", i), func(t *testing.T) {
+			got := fmtDate(tt.in.BrowserReleased())
+			if got != tt.want {
+				t.Errorf("\ngot:  %q\nwant: %q", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestOSEndOfLife(t *testing.T) {
+	tests := []struct {
+		in           UserAgent
+		wantReleased string
+		wantEOL      string
+	}{
+		{UserAgent{}, "", ""},
+		{UserAgent{OSName: "Linux", OSVersion: "Ubuntu"}, "", ""},
+		{UserAgent{OSName: "Windows", OSVersion: "7"}, "2009-10-22", "2020-01-14"},
+		{UserAgent{OSName: "Windows", OSVersion: "11"}, "2021-10-05", ""},
+		{UserAgent{OSName: "Windows", OSVersion: "10", Reduced: true}, "", ""},
+		{UserAgent{OSName: "Windows", OSVersion: "10", OSFromHints: true}, "2015-07-29", "2025-10-14"},
+		{UserAgent{OSName: "macOS", OSVersion: "10.15", OSFromHints: true}, "2019-10-07", "2022-10-24"},
+		{ParseUA("Mozilla/5.0 (Windows NT 10.0; Win64; x64; rv:120.0) Gecko/20100101 Firefox/120.0"), "2015-07-29", "2025-10-14"},
+		{ParseUA("Mozilla/5.0 (Macintosh; Intel Mac OS X 10_15_7) AppleWebKit/605.1.15 (KHTML, like Gecko) Version/17.1 Safari/605.1.15"), "2019-10-07", "2022-10-24"},
+		{ParseUA("Mozilla/5.0 (Macintosh; Intel Mac OS X 10.15; rv:120.0) Gecko/20100101 Firefox/120.0"), "2019-10-07", "2022-10-24"},
+		{ParseUA("Mozilla/5.0 (Windows NT 10.0; Win64; x64) AppleWebKit/537.36 (KHTML, like Gecko) Chrome/120.0.0.0 Safari/537.36"), "", ""},
+		{UserAgent{OSName: "macOS", OSVersion: "10.14"}, "2018-09-24", "2021-10-25"},
+		{UserAgent{OSName: "macOS", OSVersion: "14.1"}, "2023-09-26", ""},
+		{UserAgent{OSName: "iOS", OSVersion: "12.4"}, "2018-09-17", "2020-09-16"},
+		{UserAgent{OSName: "Android", OSVersion: "6"}, "2015-10-05", "2019-09-03"},
+		{UserAgent{OSName: "Android", OSVersion: "7.1"}, "2016-10-04", "2020-09-08"},
+		{UserAgent{OSName: "Android", OSVersion: "14"}, "2023-10-04", ""},
+	}
+
+	for i, tt := range tests {
+		t.Run(fmt.Sprintf("%d", i), func(t *testing.T) {
+			released := fmtDate(tt.in.OSReleased())
+			eol, ok := tt.in.OSEndOfLife()
+			if released != tt.wantReleased {
+				t.Errorf("released\ngot:  %q\nwant: %q", released, tt.wantReleased)
+			}
+			if fmtDate(eol) != tt.wantEOL || ok != (tt.wantEOL != "") {
+				t.Errorf("eol\ngot:  %q %t\nwant: %q", fmtDate(eol), ok, tt.wantEOL)
+			}
+		})
+	}
+}
+
+func fmtDate(t time.Time) string {
+	if t.IsZero() {
+		return ""
+	}
+	return t.Format("2006-01-02")
+}
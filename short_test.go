@@ -0,0 +1,201 @@
+package gadget
+
+import (
+	"fmt"
+	"strings"
+	"testing"
+)
+
+func TestParseShortUA(t *testing.T) {
+	for _, short := range testShortHeaders(t) {
+		want := ParseUA(UnshortenUA(short))
+		if got := ParseShortUA(short); got != want {
+			t.Errorf("ParseShortUA(%q)\ngot:  %#v\nwant: %#v", short, got, want)
+		}
+
+		b := []byte(UnshortenUA(short))
+		got := ParseUABytes(b)
+		for i := range b {
+			b[i] = 'x'
+		}
+		if got != want {
+			t.Errorf("ParseUABytes(%q)\ngot:  %#v\nwant: %#v", short, got, want)
+		}
+	}
+}
+
+func TestShortenUAVersions(t *testing.T) {
+	size := make([]int, len(shortDicts)) // Original size at index 0.
+	for _, ua := range testHeaders(t) {
+		if got, want := ShortenUA(ua), shortDicts[1].enc.Replace(ua); got != want {
+			t.Errorf("ShortenUA doesn't use version 1\ngot:  %q\nwant: %q", got, want)
+		}
+		size[0] += len(ua)
+		for v := 1; v < len(shortDicts); v++ {
+			short, _ := ShortenUAVersion(ua, v)
+			if got := UnshortenUA(short); got != ua {
+				t.Errorf("v%d doesn't round-trip\nua:    %q\nshort: %q\ngot:   %q", v, ua, short, got)
+			}
+			if err := VerifyShortUA(short); err != nil {
+				t.Error(err)
+			}
+			size[v] += len(short)
+		}
+	}
+
+	for v := 2; v < len(shortDicts); v++ {
+		if size[v] >= size[1] {
+			t.Errorf("version %d isn't shorter than version 1: %d bytes, %d bytes, %d bytes", v, size[0], size[1], size[v])
+		}
+	}
+}
+
+// Products that are common in real traffic, but not necessarily in testdata.
+func TestShortDictRequired(t *testing.T) {
+	required := []string{
+		"Win64; x64", "Intel Mac OS X", "like Mac OS X", "X11", "rv:",
+		"Edg/", "OPR/", "SamsungBrowser/", "CriOS/",
+	}
+	for v := 2; v < len(shortDicts); v++ {
+		for _, r := range required {
+			if s, _ := ShortenUAVersion(r, v); s == "~"+fmt.Sprint(v)+r {
+				t.Errorf("v%d: %q not in dictionary", v, r)
+			}
+		}
+	}
+}
+
+func TestAppendUnshorten(t *testing.T) {
+	tests := []string{
+		"",
+		"~",
+		"~~",
+		"~~~",
+		"a~",
+		"~x",
+		"~Z",
+		"~Z ~Z~Z ",
+		"~~c~c",
+		"~Z (~W NT 10.0; Win64; x64) ~a537.36 ~G ~c80.0.3987.132 ~s537.36",
+		strings.Repeat("~G", 100),
+	}
+
+	for i, tt := range tests {
+		t.Run(fmt.Sprintf("%d", i), func(t *testing.T) {
+			got := string(appendUnshorten(nil, tt))
+			want := UnshortenUA(tt)
+			if got != want {
+				t.Errorf("\ngot:  %q\nwant: %q", got, want)
+			}
+		})
+	}
+}
+
+func BenchmarkParseShortUA(b *testing.B) {
+	list := testShortHeaders(b, "top500")
+	b.Run("ParseShortUA", func(b *testing.B) {
+		b.ReportAllocs()
+		for n := 0; n < b.N; n++ {
+			ParseShortUA(list[n%len(list)])
+		}
+	})
+	b.Run("UnshortenUA", func(b *testing.B) {
+		b.ReportAllocs()
+		for n := 0; n < b.N; n++ {
+			ParseUA(UnshortenUA(list[n%len(list)]))
+		}
+	})
+}
+
+func TestShortenUAVersion(t *testing.T) {
+	defer func(d []*shortDict) { shortDicts = d }(shortDicts)
+	shortDicts = append(shortDicts[:len(shortDicts):len(shortDicts)], newShortDict([]shortToken{
+		{"~", "~~"},
+		{"Mozilla/5.0 ", "~Z"},
+		{"Firefox/", "~F"},
+	}))
+	latest := len(shortDicts) - 1
+
+	ua := "Mozilla/5.0 (X11; Linux x86_64; rv:78.0) Gecko/20100101 Firefox/78.0 ~2"
+	tests := []struct {
+		version int
+		want    string
+		wantErr string
+	}{
+		{0, "", "unknown version 0"},
+		{latest + 1, "", fmt.Sprintf("unknown version %d", latest+1)},
+		{1, "~Z (X11; ~L x86_64; rv:78.0) ~g20100101 ~f78.0 ~~2", ""},
+		{latest, fmt.Sprintf("~%d~Z(X11; Linux x86_64; rv:78.0) Gecko/20100101 ~F78.0 ~~2", latest), ""},
+	}
+
+	for i, tt := range tests {
+		t.Run(fmt.Sprintf("%d", i), func(t *testing.T) {
+			got, err := ShortenUAVersion(ua, tt.version)
+			if !errorContains(err, tt.wantErr) {
+				t.Fatalf("wrong error\ngot:  %v\nwant: %v", err, tt.wantErr)
+			}
+			if got != tt.want {
+				t.Errorf("\ngot:  %q\nwant: %q", got, tt.want)
+			}
+			if err != nil {
+				return
+			}
+
+			if v := ShortUAVersion(got); v != tt.version {
+				t.Errorf("ShortUAVersion: %d", v)
+			}
+			if u := UnshortenUA(got); u != ua {
+				t.Errorf("UnshortenUA\ngot:  %q\nwant: %q", u, ua)
+			}
+			if u := string(appendUnshorten(nil, got)); u != ua {
+				t.Errorf("appendUnshorten\ngot:  %q\nwant: %q", u, ua)
+			}
+		})
+	}
+
+	if got := ShortenUA(ua); ShortUAVersion(got) != 1 {
+		t.Errorf("ShortenUA doesn't use version 1: %q", got)
+	}
+	if got := UnshortenUA("~9abc"); got != "~9abc" {
+		t.Errorf("unknown version: %q", got)
+	}
+}
+
+func TestUnshortenUAStrict(t *testing.T) {
+	tests := []struct {
+		in, want, wantErr, wantVerify string
+	}{
+		{"", "", "", ""},
+		{"~~", "~", "", ""},
+		{"~Z (~W NT 10.0)", "Mozilla/5.0 (Windows NT 10.0)", "", ""},
+		{"~2~M (~w 10.0; ~W) ", "Mozilla/5.0 (Windows NT 10.0; Win64; x64) ", "", ""},
+		{"~", "", `dangling "~" at position 0`, `dangling "~" at position 0`},
+		{"abc~", "", `dangling "~" at position 3`, `dangling "~" at position 3`},
+		{"~2abc~", "", `dangling "~" at position 5`, `dangling "~" at position 5`},
+		{"~Zx", "", `unknown token "~Z" at position 0`, `unknown token "~Z" at position 0`},
+		{"a ~x", "", `unknown token "~x" at position 2`, `unknown token "~x" at position 2`},
+		{"~2~2", "", `unknown token "~2" at position 2`, `unknown token "~2" at position 2`},
+		{"~9abc", "", `unknown version "~9"`, `unknown version "~9"`},
+		{"~0abc", "", `unknown version "~0"`, `unknown version "~0"`},
+		{"~12abc", "", `unknown version "~1"`, `unknown version "~1"`},
+		{"Chrome/80", "Chrome/80", "", `"Chrome/80" doesn't round-trip: it encodes as "~c80"`},
+		{"~2Chrome/80", "Chrome/80", "", `"~2Chrome/80" doesn't round-trip: it encodes as "~2~c80"`},
+	}
+
+	for i, tt := range tests {
+		t.Run(fmt.Sprintf("%d", i), func(t *testing.T) {
+			got, err := UnshortenUAStrict(tt.in)
+			if !errorContains(err, tt.wantErr) {
+				t.Fatalf("wrong error\ngot:  %v\nwant: %v", err, tt.wantErr)
+			}
+			if got != tt.want {
+				t.Errorf("\ngot:  %q\nwant: %q", got, tt.want)
+			}
+
+			err = VerifyShortUA(tt.in)
+			if !errorContains(err, tt.wantVerify) {
+				t.Errorf("wrong VerifyShortUA error\ngot:  %v\nwant: %v", err, tt.wantVerify)
+			}
+		})
+	}
+}
@@ -0,0 +1,35 @@
+//go:build go1.21
+// +build go1.21
+
+package gadget
+
+import "log/slog"
+
+// LogValue implements slog.LogValuer.
+//
+// This uses the same names as MarshalJSON(); model, app, reduced,
+// os_from_hints, and duplicate are omitted if they're empty.
+func (u UserAgent) LogValue() slog.Value {
+	attrs := make([]slog.Attr, 0, 9)
+	attrs = append(attrs,
+		slog.String("browser_name", u.BrowserName),
+		slog.String("browser_version", u.BrowserVersion),
+		slog.String("os_name", u.OSName),
+		slog.String("os_version", u.OSVersion))
+	if u.Model != "" {
+		attrs = append(attrs, slog.String("model", u.Model))
+	}
+	if u.App != "" {
+		attrs = append(attrs, slog.String("app", u.App))
+	}
+	if u.Reduced {
+		attrs = append(attrs, slog.Bool("reduced", true))
+	}
+	if u.OSFromHints {
+		attrs = append(attrs, slog.Bool("os_from_hints", true))
+	}
+	if u.Duplicate {
+		attrs = append(attrs, slog.Bool("duplicate", true))
+	}
+	return slog.GroupValue(attrs...)
+}
@@ -0,0 +1,31 @@
+//go:build go1.21
+// +build go1.21
+
+package gadget
+
+import (
+	"bytes"
+	"log/slog"
+	"testing"
+)
+
+func TestLogValue(t *testing.T) {
+	buf := new(bytes.Buffer)
+	l := slog.New(slog.NewTextHandler(buf, &slog.HandlerOptions{
+		ReplaceAttr: func(_ []string, a slog.Attr) slog.Attr {
+			if a.Key == slog.TimeKey {
+				return slog.Attr{}
+			}
+			return a
+		},
+	}))
+
+	l.Info("x", "ua", UserAgent{BrowserName: "Firefox", BrowserVersion: "73", OSName: "Windows", OSVersion: "10"})
+	l.Info("x", "ua", UserAgent{BrowserName: "Chrome", Model: "Pixel 7", Reduced: true})
+
+	want := "level=INFO msg=x ua.browser_name=Firefox ua.browser_version=73 ua.os_name=Windows ua.os_version=10\n" +
+		"level=INFO msg=x ua.browser_name=Chrome ua.browser_version=\"\" ua.os_name=\"\" ua.os_version=\"\" ua.model=\"Pixel 7\" ua.reduced=true\n"
+	if got := buf.String(); got != want {
+		t.Errorf("\ngot:\n%s\nwant:\n%s", got, want)
+	}
+}
@@ -0,0 +1,239 @@
+package gadget
+
+import (
+	"bytes"
+	"fmt"
+	"io"
+	"strconv"
+)
+
+// ShortenWriter shortens everything written to it with ShortenUA().
+//
+// Every line is shortened separately, including the version marker, so the
+// lines can be read back with UnshortenUA() as well as UnshortenReader.
+type ShortenWriter struct {
+	w      io.Writer
+	d      *shortDict
+	first  *[256][]int // shortenIndex for d.
+	marker string
+	buf    []byte // Input we can't shorten yet.
+	out    []byte
+	inLine bool
+	err    error
+}
+
+// NewShortenWriter creates a new ShortenWriter, using version 1 of the
+// dictionary like ShortenUA().
+//
+// Close must be called to write any remaining data; this doesn't close w.
+func NewShortenWriter(w io.Writer) *ShortenWriter {
+	return &ShortenWriter{w: w, d: shortDicts[1], first: &shortenIndex[1]}
+}
+
+// NewShortenWriterVersion creates a new ShortenWriter, using a specific version
+// of the dictionary like ShortenUAVersion().
+func NewShortenWriterVersion(w io.Writer, version int) (*ShortenWriter, error) {
+	if version < 1 || version >= len(shortDicts) {
+		return nil, fmt.Errorf("gadget.NewShortenWriterVersion: unknown version %d", version)
+	}
+	sw := &ShortenWriter{w: w, d: shortDicts[version], first: &shortenIndex[version]}
+	if version > 1 {
+		sw.marker = "~" + strconv.Itoa(version)
+	}
+	return sw, nil
+}
+
+// Write shortens p and writes it to the underlying writer.
+//
+// Data at the end of p that may be the start of a token is kept until the
+// next Write or Close.
+func (w *ShortenWriter) Write(p []byte) (int, error) {
+	if w.err != nil {
+		return 0, w.err
+	}
+	w.buf = append(w.buf, p...)
+	w.shorten(false)
+	if err := w.flush(); err != nil {
+		return 0, err
+	}
+	return len(p), nil
+}
+
+// Close writes any remaining data to the underlying writer.
+func (w *ShortenWriter) Close() error {
+	if w.err != nil {
+		return w.err
+	}
+	w.shorten(true)
+	return w.flush()
+}
+
+func (w *ShortenWriter) flush() error {
+	if len(w.out) > 0 {
+		_, w.err = w.w.Write(w.out)
+		w.out = w.out[:0]
+	}
+	return w.err
+}
+
+// Tokens for ShortenWriter, indexed by the first byte of the long string.
+var shortenIndex = func() [][256][]int {
+	idx := make([][256][]int, len(shortDicts))
+	for v, d := range shortDicts {
+		if d == nil {
+			continue
+		}
+		for i, t := range d.tokens {
+			idx[v][t.long[0]] = append(idx[v][t.long[0]], i)
+		}
+	}
+	return idx
+}()
+
+// Shorten as much of the buffer as possible; if final is true then all of it.
+//
+// Tokens are tried in the same order as strings.Replacer does, so if a token
+// that comes first might match but there isn't enough data yet we need to wait
+// for more, even if a later token matches.
+func (w *ShortenWriter) shorten(final bool) {
+	i := 0
+outer:
+	for i < len(w.buf) {
+		if !w.inLine {
+			w.out = append(w.out, w.marker...)
+			w.inLine = true
+		}
+		c := w.buf[i]
+		if c == '\n' {
+			w.out = append(w.out, c)
+			w.inLine = false
+			i++
+			continue
+		}
+
+		rest := w.buf[i:]
+		for _, t := range w.first[c] {
+			long := w.d.tokens[t].long
+			if len(rest) >= len(long) {
+				if string(rest[:len(long)]) == long {
+					w.out = append(w.out, w.d.tokens[t].short...)
+					i += len(long)
+					continue outer
+				}
+				continue
+			}
+			if !final && string(rest) == long[:len(rest)] {
+				break outer
+			}
+		}
+		w.out = append(w.out, c)
+		i++
+	}
+	w.buf = w.buf[:copy(w.buf, w.buf[i:])]
+}
+
+// UnshortenReader reads data shortened with ShortenUA() or ShortenWriter, and
+// unshortens it.
+//
+// Every line is unshortened separately, so every line can have a different
+// version.
+type UnshortenReader struct {
+	r      io.Reader
+	d      *shortDict // Version of the current line; nil at the start of a line.
+	raw    bool       // Unknown version; copy the line as-is.
+	read   [4096]byte
+	buf    []byte // Input we can't unshorten yet.
+	out    []byte // Unshortened data not yet returned from Read().
+	outPos int
+	eof    bool
+}
+
+// NewUnshortenReader creates a new UnshortenReader.
+func NewUnshortenReader(r io.Reader) *UnshortenReader {
+	return &UnshortenReader{r: r}
+}
+
+// Read unshortened data.
+//
+// Errors other than io.EOF from the underlying reader are returned as-is, but
+// data that may be the start of a token is kept, so Read can be called again
+// after e.g. a timeout.
+func (r *UnshortenReader) Read(p []byte) (int, error) {
+	for r.outPos == len(r.out) {
+		if r.eof {
+			return 0, io.EOF
+		}
+		r.out, r.outPos = r.out[:0], 0
+
+		n, err := r.r.Read(r.read[:])
+		r.buf = append(r.buf, r.read[:n]...)
+		r.eof = err == io.EOF
+		r.unshorten(r.eof)
+		if err != nil && !r.eof {
+			r.outPos = copy(p, r.out)
+			return r.outPos, err
+		}
+	}
+
+	n := copy(p, r.out[r.outPos:])
+	r.outPos += n
+	return n, nil
+}
+
+// Unshorten as much of the buffer as possible; if final is true then all of
+// it.
+func (r *UnshortenReader) unshorten(final bool) {
+	i := 0
+	for i < len(r.buf) {
+		if r.d == nil && !r.raw {
+			// Need two bytes to get the version.
+			if r.buf[i] == '~' && i+1 == len(r.buf) && !final {
+				break
+			}
+			r.d = shortDicts[1]
+			if r.buf[i] == '~' && i+1 < len(r.buf) && isNumber(r.buf[i+1]) {
+				if v := int(r.buf[i+1] - '0'); v >= 2 && v < len(shortDicts) {
+					r.d = shortDicts[v]
+					i += 2
+				} else {
+					r.d, r.raw = nil, true
+				}
+			}
+			continue
+		}
+
+		c := r.buf[i]
+		if c == '\n' {
+			r.out = append(r.out, c)
+			r.d, r.raw = nil, false
+			i++
+			continue
+		}
+		if r.raw || c != '~' {
+			r.out = append(r.out, c)
+			i++
+			continue
+		}
+
+		if i+1 == len(r.buf) && !final {
+			break
+		}
+		if i+1 < len(r.buf) {
+			if t := r.d.index[r.buf[i+1]]; t > 0 {
+				tok := r.d.tokens[t-1]
+				rest := r.buf[i:]
+				if len(rest) < len(tok.short) && !final && tok.short[:len(rest)] == string(rest) {
+					break
+				}
+				if bytes.HasPrefix(rest, []byte(tok.short)) {
+					r.out = append(r.out, tok.long...)
+					i += len(tok.short)
+					continue
+				}
+			}
+		}
+		r.out = append(r.out, '~')
+		i++
+	}
+	r.buf = r.buf[:copy(r.buf, r.buf[i:])]
+}
@@ -0,0 +1,214 @@
+package gadget
+
+import (
+	"bytes"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"math/rand"
+	"strings"
+	"testing"
+	"testing/iotest"
+)
+
+// Write in random chunks.
+type chunkWriter struct {
+	w io.Writer
+	r *rand.Rand
+}
+
+func (c chunkWriter) write(p []byte) error {
+	for len(p) > 0 {
+		n := c.r.Intn(40) + 1
+		if n > len(p) {
+			n = len(p)
+		}
+		if _, err := c.w.Write(p[:n]); err != nil {
+			return err
+		}
+		p = p[n:]
+	}
+	return nil
+}
+
+func TestShortenWriter(t *testing.T) {
+	long := testHeaders(t)
+	long = append(long, "", "~", "~~", "~2", "a~", "Mobile", "Mobil", "Mozilla/5.0", "Linux x86_64")
+
+	tests := []string{
+		"",
+		"\n",
+		"\n\n",
+		"Mobile",
+		"Mobile/",
+		"Linux x86_64; rv:\nLinux x86_64",
+		strings.Join(long, "\n"),
+		strings.Join(long, "\n") + "\n",
+	}
+
+	for i, tt := range tests {
+		for v := 1; v < len(shortDicts); v++ {
+			t.Run(fmt.Sprintf("%d/v%d", i, v), func(t *testing.T) {
+				testShortenWriter(t, tt, v)
+			})
+		}
+	}
+
+	if _, err := NewShortenWriterVersion(nil, len(shortDicts)); !errorContains(err, "unknown version") {
+		t.Errorf("wrong error: %v", err)
+	}
+}
+
+func testShortenWriter(t *testing.T, tt string, v int) {
+	var want []string
+	if tt != "" {
+		for _, l := range strings.Split(strings.TrimSuffix(tt, "\n"), "\n") {
+			s, _ := ShortenUAVersion(l, v)
+			want = append(want, s)
+		}
+	}
+	wantS := strings.Join(want, "\n")
+	if strings.HasSuffix(tt, "\n") {
+		wantS += "\n"
+	}
+
+	for _, seed := range []int64{1, 2, 3} {
+		buf := new(bytes.Buffer)
+		w, _ := NewShortenWriterVersion(buf, v)
+		if v == 1 {
+			w = NewShortenWriter(buf)
+		}
+		if err := (chunkWriter{w, rand.New(rand.NewSource(seed))}).write([]byte(tt)); err != nil {
+			t.Fatal(err)
+		}
+		if err := w.Close(); err != nil {
+			t.Fatal(err)
+		}
+
+		if got := buf.String(); got != wantS {
+			gotL, wantL := strings.Split(got, "\n"), strings.Split(wantS, "\n")
+			for j := range wantL {
+				if j >= len(gotL) || gotL[j] != wantL[j] {
+					t.Fatalf("seed %d: line %d\ngot:  %q\nwant: %q", seed, j, gotL[j], wantL[j])
+				}
+			}
+			t.Fatalf("seed %d\ngot:  %q\nwant: %q", seed, got, wantS)
+		}
+	}
+}
+
+func TestUnshortenReader(t *testing.T) {
+	short, long := testShortHeaders(t), testHeaders(t)
+
+	var v2 []string
+	for _, l := range long {
+		s, _ := ShortenUAVersion(l, 2)
+		v2 = append(v2, s)
+	}
+
+	tests := []struct {
+		in, want string
+	}{
+		{"", ""},
+		{"\n", "\n"},
+		{"~", "~"},
+		{"~Z", "~Z"},
+		{"~Z \n~Z", "Mozilla/5.0 \n~Z"},
+		{"~2\n~2~M (\n~9~Z\n~Z ", "\nMozilla/5.0 (\n~9~Z\nMozilla/5.0 "},
+		{strings.Join(short, "\n"), strings.Join(long, "\n")},
+		{strings.Join(v2, "\n") + "\n", strings.Join(long, "\n") + "\n"},
+	}
+
+	for i, tt := range tests {
+		t.Run(fmt.Sprintf("%d", i), func(t *testing.T) {
+			// Should be identical to UnshortenUA() on every line.
+			var want []string
+			for _, l := range strings.Split(tt.in, "\n") {
+				want = append(want, UnshortenUA(l))
+			}
+			if w := strings.Join(want, "\n"); w != tt.want {
+				t.Fatalf("wrong test: UnshortenUA gives %q", w)
+			}
+
+			readers := map[string]func(io.Reader) io.Reader{
+				"byte": iotest.OneByteReader,
+				"half": iotest.HalfReader,
+				"data": iotest.DataErrReader,
+			}
+			for name, wrap := range readers {
+				got, err := ioutil.ReadAll(NewUnshortenReader(wrap(strings.NewReader(tt.in))))
+				if err != nil {
+					t.Fatal(err)
+				}
+				if string(got) != tt.want {
+					t.Errorf("%s\ngot:  %q\nwant: %q", name, got, tt.want)
+				}
+			}
+		})
+	}
+}
+
+func TestStreamRoundTrip(t *testing.T) {
+	long := testHeaders(t)
+	in := strings.Join(long, "\n")
+
+	r, w := io.Pipe()
+	go func() {
+		sw := NewShortenWriter(w)
+		_ = (chunkWriter{sw, rand.New(rand.NewSource(1))}).write([]byte(in))
+		w.CloseWithError(sw.Close())
+	}()
+
+	got, err := ioutil.ReadAll(NewUnshortenReader(r))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if string(got) != in {
+		t.Error("doesn't round-trip")
+	}
+}
+
+// Read the data and errors in order.
+type stepReader []struct {
+	data string
+	err  error
+}
+
+func (r *stepReader) Read(p []byte) (int, error) {
+	if len(*r) == 0 {
+		return 0, io.EOF
+	}
+	s := (*r)[0]
+	*r = (*r)[1:]
+	return copy(p, s.data), s.err
+}
+
+func TestUnshortenReaderError(t *testing.T) {
+	r := NewUnshortenReader(&stepReader{
+		{"a ~", nil},
+		{"", iotest.ErrTimeout},
+		{"Z ~", iotest.ErrTimeout},
+		{"c", nil},
+		{"", iotest.ErrTimeout},
+		{"~", io.EOF},
+	})
+
+	var (
+		got  []string
+		errs []error
+		p    = make([]byte, 100)
+	)
+	for i := 0; i < 20; i++ { // Don't loop forever if it never returns io.EOF.
+		n, err := r.Read(p)
+		if err == io.EOF {
+			break
+		}
+		got, errs = append(got, string(p[:n])), append(errs, err)
+	}
+
+	want := []string{"a ", "", "Mozilla/5.0 ", "Chrome/", "", "~"}
+	wantErrs := []error{nil, iotest.ErrTimeout, iotest.ErrTimeout, nil, iotest.ErrTimeout, nil}
+	if fmt.Sprint(got) != fmt.Sprint(want) || fmt.Sprint(errs) != fmt.Sprint(wantErrs) {
+		t.Errorf("\ngot:  %q %v\nwant: %q %v", got, errs, want, wantErrs)
+	}
+}
@@ -1,7 +1,7 @@
 package gadget
 
 import (
-	"fmt"
+	"strconv"
 	"strings"
 )
 
@@ -99,122 +99,65 @@ var (
 		"PaleMoon/",
 		"Basilisk/",
 	}
-)
 
-var (
-	/* sed to do the same:
-
-	sed \
-		-e 's!~!~~!g;' \
-		-e 's!Android!~A!g;' \
-		-e 's!Chrome/!~c!g;' \
-		-e 's!compatible!~C!g;' \
-		-e 's!Edge/!~e!g;' \
-		-e 's!Firefox/!~f!g;' \
-		-e 's!Gecko/!~g!g;' \
-		-e 's!(KHTML, like Gecko)!~G!g;' \
-		-e 's!iPhone!~i!g;' \
-		-e 's!Macintosh!~I!g;' \
-		-e 's!AppleWebKit/!~a!g;' \
-		-e 's!Linux!~L!g;' \
-		-e 's!Mobile/!~m!g;' \
-		-e 's!Mobile!~M!g;' \
-		-e 's!Safari/!~s!g;' \
-		-e 's!Version/!~v!g;' \
-		-e 's!Windows!~W!g;' \
-		-e 's!Mozilla/5.0 !~Z !g;' \
-		< /dev/stdin
-
-		To replace all cases in a test file, save it to "sort" and do something like:
-		:%s/\v(\t(.*)?\t)(.*)/\=submatch(1) . system('short', submatch(3))/
-	*/
-
-	uaShortener = strings.NewReplacer(
-		"~", "~~", // Preserve ~ and decode lossly.
-		"Android", "~A",
-		"Chrome/", "~c",
-		"compatible", "~C",
-		"Edge/", "~e",
-		"Firefox/", "~f",
-		"Gecko/", "~g",
-		"(KHTML, like Gecko)", "~G",
-		"iPhone", "~i",
-		"Macintosh", "~I",
-		"AppleWebKit/", "~a",
-		"Linux", "~L",
-		"Mobile/", "~m",
-		"Mobile", "~M",
-		"Safari/", "~s",
-		"Version/", "~v",
-		"Windows", "~W",
-		"Mozilla/5.0 ", "~Z ")
-
-	// shortUADecoder is the inverse of uaShortener.
-	shortUADecoder = strings.NewReplacer(
-		"~~", "~",
-		"~A", "Android",
-		"~c", "Chrome/",
-		"~C", "compatible",
-		"~e", "Edge/",
-		"~f", "Firefox/",
-		"~g", "Gecko/",
-		"~G", "(KHTML, like Gecko)",
-		"~i", "iPhone",
-		"~I", "Macintosh",
-		"~a", "AppleWebKit/",
-		"~L", "Linux",
-		"~m", "Mobile/",
-		"~M", "Mobile",
-		"~s", "Safari/",
-		"~v", "Version/",
-		"~W", "Windows",
-		"~Z ", "Mozilla/5.0 ")
+	// The "frozen" system strings Chrome sends since the User-Agent reduction;
+	// these are sent regardless of the actual device or OS version.
+	// https://www.chromium.org/updates/ua-reduction/
+	reducedSystems = [][]string{
+		{"Linux", "Android 10", "K"},
+		{"Windows NT 10.0", "Win64", "x64"},
+		{"Macintosh", "Intel Mac OS X 10_15_7"},
+		{"X11", "Linux x86_64"},
+		{"X11", "CrOS x86_64 14541.0.0"},
+		{"Fuchsia"},
+	}
 )
 
-// ShortenUA shortens a User-Agent string by replacing common strings with small
-// tokens.
-//
-// Use UnshortenUA() to reverse it.
-//
-// Example:
-//
-//   Mozilla/5.0 (Windows NT 10.0; Win64; x64) AppleWebKit/537.36 (KHTML, like Gecko) Chrome/80.0.3987.132 Safari/537.36
-//   ~Z (~W NT 10.0; Win64; x64) ~a537.36 ~G ~c80.0.3987.132 ~s537.36
-//
-// The goal is not to produce the shortest output, but to provide a reasonably
-// short output while maintaining readability.
-//
-// Inspired by: https://github.com/icza/gox/blob/master/netx/httpx/httpx.go
-func ShortenUA(ua string) string { return uaShortener.Replace(ua) }
-
-// UnshortenUA reverses ShortenUA().
-func UnshortenUA(short string) string { return shortUADecoder.Replace(short) }
-
 type UserAgent struct {
 	BrowserName    string
 	BrowserVersion string
 	OSName         string
 	OSVersion      string
+
+	// Reduced is set if this looks like a "reduced" Chrome User-Agent, which
+	// always sends the same OS version (e.g. "Android 10" or "Windows 10")
+	// and a minor version of ".0.0.0" regardless of the actual system. The
+	// OSVersion is unreliable if this is set.
+	//
+	// Parse() will clear this if the OS version was resolved from the Client
+	// Hints headers.
+	Reduced bool
+
+	// OSFromHints is set if the OS version is from the Client Hints rather
+	// than the User-Agent header. This is only set by Parse() and
+	// ParseUAData().
+	OSFromHints bool
+
+	// Duplicate is set if Parse() saw more than one User-Agent header.
+	Duplicate bool
+
+	// Model is the device model, such as "Pixel 7". This is only set from
+	// Client Hints in Parse() and ParseUAData().
+	Model string
+
+	// App is the application embedding the browser, such as
+	// "com.example.app" for an Android WebView. This is only set by Parse().
+	App string
 }
 
 // String shows the full Browser and OS name as "<browser> on <os>". If either
 // one is blank the "on" will be omitted.
-func (u UserAgent) String() string {
-	if u.OS() == "" {
-		return u.Browser()
-	}
-	if u.Browser() == "" {
-		return u.OS()
-	}
-	return fmt.Sprintf("%s on %s", u.Browser(), u.OS())
-}
+//
+// Use Join() to use a different connector than "on", or Format() for more
+// control.
+func (u UserAgent) String() string { return u.Join("on") }
 
 // Browser gets the full browser, including the version (if any).
 func (u UserAgent) Browser() string {
 	if u.BrowserVersion == "" {
 		return u.BrowserName
 	}
-	return fmt.Sprintf("%s %s", u.BrowserName, u.BrowserVersion)
+	return u.BrowserName + " " + u.BrowserVersion
 }
 
 // OS gets the full operating system, including the version (if any).
@@ -222,12 +165,45 @@ func (u UserAgent) OS() string {
 	if u.OSVersion == "" {
 		return u.OSName
 	}
-	return fmt.Sprintf("%s %s", u.OSName, u.OSVersion)
+	return u.OSName + " " + u.OSVersion
+}
+
+// AppendString appends String() to b and returns the extended buffer.
+func (u UserAgent) AppendString(b []byte) []byte { return u.AppendJoin(b, "on") }
+
+// AppendBrowser appends Browser() to b and returns the extended buffer.
+func (u UserAgent) AppendBrowser(b []byte) []byte {
+	b = append(b, u.BrowserName...)
+	if u.BrowserVersion != "" {
+		b = append(append(b, ' '), u.BrowserVersion...)
+	}
+	return b
+}
+
+// AppendOS appends OS() to b and returns the extended buffer.
+func (u UserAgent) AppendOS(b []byte) []byte {
+	b = append(b, u.OSName...)
+	if u.OSVersion != "" {
+		b = append(append(b, ' '), u.OSVersion...)
+	}
+	return b
 }
 
 // ParseUA parses a User-Agent header.
+//
+// This doesn't allocate, unless the header has a very large number of
+// products or system entries.
 func ParseUA(uaHeader string) UserAgent {
-	p := parse(uaHeader)
+	b, ok := parse(uaHeader)
+	if !ok {
+		// Copy uaHeader so it's not stored on the heap by parseAlloc(), which
+		// would make uaHeader escape to the heap for all callers.
+		return parseProps(uaHeader, parseAlloc(cloneString(uaHeader)))
+	}
+	return parseProps(uaHeader, props{system: b.system[:b.nsys], products: b.products[:b.nprod]})
+}
+
+func parseProps(uaHeader string, p props) UserAgent {
 	ua := UserAgent{}
 	if len(p.products) == 0 {
 		return ua
@@ -258,7 +234,7 @@ func ParseUA(uaHeader string) UserAgent {
 			case strings.HasPrefix(s, "Intel Mac OS X"):
 				ua.OSName = "macOS"
 				if len(s) > 14 {
-					ua.OSVersion = maxVersion(strings.ReplaceAll(after(s, 15), "_", "."), 2, false)
+					ua.OSVersion = maxVersionUnderscore(after(s, 15), 2)
 				}
 				break oloop
 
@@ -271,15 +247,21 @@ func ParseUA(uaHeader string) UserAgent {
 					if j := strings.IndexRune(v, ' '); j > -1 {
 						v = v[:j]
 					}
-					ua.OSVersion = maxVersion(strings.ReplaceAll(v, "_", "."), 2, false)
+					ua.OSVersion = maxVersionUnderscore(v, 2)
 				}
 				break oloop
 
 			case strings.HasPrefix(s, "Windows Phone"):
 				ua.OSName = "Windows Phone"
-				sp := strings.Split(s, " ")
-				if len(sp) > 2 {
-					ua.OSVersion = maxVersion(sp[2], 2, true)
+				// Windows Phone 8.0; use the third word.
+				if i := strings.IndexByte(s, ' '); i > -1 {
+					if j := strings.IndexByte(s[i+1:], ' '); j > -1 {
+						v := s[i+j+2:]
+						if k := strings.IndexByte(v, ' '); k > -1 {
+							v = v[:k]
+						}
+						ua.OSVersion = maxVersion(v, 2, true)
+					}
 				}
 				break oloop
 
@@ -340,6 +322,8 @@ func ParseUA(uaHeader string) UserAgent {
 		}
 	}
 
+	m := scanProducts(p.products)
+
 	if ua.OSName == "Linux" {
 		for _, s := range p.system {
 			if s == "Ubuntu" || s == "CentOS" || s == "Fedora" || s == "Debian" {
@@ -347,13 +331,8 @@ func ParseUA(uaHeader string) UserAgent {
 				break
 			}
 		}
-		if ua.OSVersion == "" {
-			for _, s := range p.products {
-				if s == "Ubuntu" || s == "CentOS" || s == "Fedora" || s == "Debian" {
-					ua.OSVersion = s
-					break
-				}
-			}
+		if ua.OSVersion == "" && m.distro > -1 {
+			ua.OSVersion = p.products[m.distro]
 		}
 	}
 
@@ -371,135 +350,103 @@ func ParseUA(uaHeader string) UserAgent {
 
 	// KaiOS puts their OS in the product, but looks like it's fairly common in e.g.
 	// India, so do special tricks.
-	for _, s := range p.products {
-		if strings.HasPrefix(s, "KAIOS/") {
-			ua.OSName = "KaiOS"
-			ua.OSVersion = maxVersion(after(s, 6), 2, false)
-			break
-		}
+	if m.kaios > -1 {
+		ua.OSName = "KaiOS"
+		ua.OSVersion = maxVersion(after(p.products[m.kaios], 6), 2, false)
 	}
 
 	// Get browser info.
 	{
 		// Get "known browsers" first.
-		for _, s := range p.products {
-			for _, k := range knownBrowsers {
-				if strings.HasPrefix(s, k) {
-					slash := strings.IndexRune(s, '/')
-					if slash > -1 {
-						ua.BrowserName = s[:slash]
-						ua.BrowserVersion = maxVersion(s[slash+1:], 2, false)
-					}
-
-					return ua
-				}
+		if m.known > -1 {
+			s := p.products[m.known]
+			slash := strings.IndexRune(s, '/')
+			if slash > -1 {
+				ua.BrowserName = s[:slash]
+				ua.BrowserVersion = maxVersion(s[slash+1:], 2, false)
 			}
+			return ua
 		}
 
-	bloop:
-		for _, s := range p.products {
+		// We need to do all sort of tricks for Safari :-/
+		safari := m.safari > -1 && (ua.OSName == "macOS" || ua.OSName == "iOS") &&
+			(m.safariVersion > -1 || m.ios)
+
+		switch {
+		case m.browser > -1 && (!safari || m.browser < m.safari):
+			s := p.products[m.browser]
 			switch {
-			case strings.HasPrefix(s, "Chrome/"):
+			case m.kind&prodChrome != 0:
 				// EdgeHTML identifies as Chrome, even though it's not.
-				for _, s2 := range p.products {
-					if strings.HasPrefix(s2, "Edge/") {
-						v := maxVersion(after(s2, 5), 1, false)
-						if len(v) > 0 && v[0] == '1' {
-							ua.BrowserName = "Edge"
-							ua.BrowserVersion = v
-						}
-						break bloop
+				if m.edge > -1 {
+					v := maxVersion(after(p.products[m.edge], 5), 1, false)
+					if len(v) > 0 && v[0] == '1' {
+						ua.BrowserName = "Edge"
+						ua.BrowserVersion = v
 					}
+					break
 				}
-
 				ua.BrowserName = "Chrome"
 				ua.BrowserVersion = maxVersion(after(s, 7), 1, false)
-				break bloop
 
-			case strings.HasPrefix(s, "Chromium/"):
+			case m.kind&prodChromium != 0:
 				ua.BrowserName = "Chrome"
 				ua.BrowserVersion = maxVersion(after(s, 9), 1, false)
-				break bloop
 
-			case strings.HasPrefix(s, "HeadlessChrome/"):
+			case m.kind&prodHeadless != 0:
 				ua.BrowserName = "Chrome"
 				ua.BrowserVersion = maxVersion(after(s, 15), 1, false)
-				break bloop
 
-			case strings.HasPrefix(s, "Firefox/"):
+			case m.kind&prodFirefox != 0:
 				ua.BrowserName = "Firefox"
 				ua.BrowserVersion = maxVersion(after(s, 8), 1, false)
-				break bloop
 
-			case strings.HasPrefix(s, "Opera/"):
+			case m.kind&prodOpera != 0:
 				for _, s2 := range p.system {
 					if strings.HasPrefix(s2, "Opera Mini/") {
 						ua.BrowserName = "Opera Mini"
 						ua.BrowserVersion = maxVersion(after(s2, 11), 2, false)
-						break bloop
+						break
 					}
 				}
+				if ua.BrowserName != "" {
+					break
+				}
 
 				ua.BrowserName = "Opera"
-				for _, s2 := range p.products {
-					if strings.HasPrefix(s2, "Version/") {
-						ua.BrowserVersion = maxVersion(after(s2, 8), 2, false)
-					}
+				if m.version > -1 {
+					ua.BrowserVersion = maxVersion(after(p.products[m.version], 8), 2, false)
 				}
-
 				if ua.BrowserVersion == "" {
 					ua.BrowserVersion = maxVersion(after(s, 6), 2, false)
 				}
+			}
 
-				break bloop
-
-			// We need to do all sort of tricks for Safari :-/
-			case (ua.OSName == "macOS" || ua.OSName == "iOS") && (strings.HasPrefix(s, "Safari/") || s == "Mobile/15E148"):
-				var (
-					isSafari bool
-					version  string
-					webkit   string
-				)
-				for _, s2 := range p.products {
-					if strings.HasPrefix(s2, "Version/") && !strings.Contains(s2, "15E") {
-						version = after(s2, 8)
-						isSafari = true
-					}
-					if strings.HasPrefix(s2, "AppleWebKit/") {
-						webkit = s2
-					}
-
-					if strings.HasPrefix(s2, "FxiOS/") || strings.HasPrefix(s2, "CriOS/") || strings.HasPrefix(s2, "Mobile/") {
-						isSafari = true
-					}
-				}
-				if isSafari {
-					ua.BrowserName = "Safari"
-					if version != "" {
-						// TODO: maybe just use maxVersion of 1? Not sure how
-						// meaningful the different between Safari 12.0 and 12.1
-						// is?
-						ua.BrowserVersion = maxVersion(version, 2, false)
-					} else if webkit != "" {
-						ua.BrowserVersion = safariVersions[after(webkit, 12)]
-					}
-					break bloop
-				}
+		case safari:
+			ua.BrowserName = "Safari"
+			var version string
+			if m.safariVersion > -1 {
+				version = after(p.products[m.safariVersion], 8)
+			}
+			if version != "" {
+				// TODO: maybe just use maxVersion of 1? Not sure how
+				// meaningful the different between Safari 12.0 and 12.1
+				// is?
+				ua.BrowserVersion = maxVersion(version, 2, false)
+			} else if m.webkit > -1 {
+				ua.BrowserVersion = safariVersions[after(p.products[m.webkit], 12)]
 			}
 		}
 
 		if ua.BrowserName == "" {
 			// Only look at the first product; reading over ignored ones seems
 			// to mostly result in noise, rather than helpful results.
-			first := p.products[0]
-
-			for _, ig := range ignoreProduct {
-				if strings.HasPrefix(first, ig) {
-					return ua
-				}
+			if m.first&prodIgnore != 0 {
+				return ua
 			}
 
 			// No /, no browser.
+			first := p.products[0]
 			s := strings.IndexRune(first, '/')
 			if s > 0 && s < len(first)-1 && isNumber(first[s+1]) && isLetter(first[s-1]) {
 				ua.BrowserName = first[:s]
@@ -508,44 +455,146 @@ func ParseUA(uaHeader string) UserAgent {
 		}
 	}
 
+	if ua.BrowserName == "Chrome" {
+		ua.Reduced = m.frozen && isReduced(p.system)
+	}
 	return ua
 }
 
+// isReduced reports if this is one of the reducedSystems; the Chrome version
+// should also be frozen to .0.0.0 for this to be a reduced User-Agent.
+func isReduced(system []string) bool {
+sloop:
+	for _, sys := range reducedSystems {
+		if len(sys) != len(system) {
+			continue
+		}
+		for i := range sys {
+			if sys[i] != system[i] {
+				continue sloop
+			}
+		}
+		return true
+	}
+	return false
+}
+
 type props struct {
 	system   []string // System information between (..)
 	products []string // All the Foo/ver products
 }
 
-func parse(ua string) props {
-	ua = strings.Trim(ua, "'") // Some clients wrap their UA in this.
-	p := props{}
-
-	s := strings.IndexRune(ua, '(')
-	e := strings.IndexRune(ua, ')')
-	if e < s {
-		e = -1
-		s = -1
-	}
-	if s > -1 && e > -1 {
-		p.system = strings.Split(ua[s+1:e], ";")
-		for i := range p.system {
-			p.system[i] = strings.TrimSpace(p.system[i])
+// propsBuf stores the props without allocating; this has room for all but the
+// most unusual User-Agents.
+type propsBuf struct {
+	system      [16]string
+	products    [32]string
+	nsys, nprod int
+}
+
+// parse the UA in to the system and products.
+//
+// This returns false if it doesn't fit in propsBuf, in which case parseAlloc()
+// should be used. The strings are only stored in the returned propsBuf, as
+// storing them anywhere else makes ua escape to the heap.
+func parse(ua string) (propsBuf, bool) {
+	var (
+		b             propsBuf
+		system, prods = splitUA(ua)
+	)
+	for {
+		v, ok := system.next()
+		if !ok {
+			break
+		}
+		if b.nsys == len(b.system) {
+			return b, false
+		}
+		b.system[b.nsys] = v
+		b.nsys++
+	}
+	for i := range prods {
+		for {
+			v, ok := prods[i].next()
+			if !ok {
+				break
+			}
+			if b.nprod == len(b.products) {
+				return b, false
+			}
+			b.products[b.nprod] = v
+			b.nprod++
 		}
 	}
+	return b, true
+}
 
-	if e > -1 && s > -1 {
-		p.products = strings.Split(
-			strings.TrimSpace(ua[:s])+" "+strings.TrimSpace(ua[e+1:]), " ")
-	} else {
-		p.products = strings.Split(ua, " ")
+// parseAlloc is like parse(), but works for any number of products or system
+// entries.
+func parseAlloc(ua string) props {
+	var (
+		p             props
+		system, prods = splitUA(ua)
+	)
+	for {
+		v, ok := system.next()
+		if !ok {
+			break
+		}
+		p.system = append(p.system, v)
 	}
-	for i := range p.products {
-		p.products[i] = strings.TrimSpace(p.products[i])
+	for i := range prods {
+		for {
+			v, ok := prods[i].next()
+			if !ok {
+				break
+			}
+			p.products = append(p.products, v)
+		}
 	}
-
 	return p
 }
 
+// Split the UA in to the system entries between the first "(" and ")" and the
+// products before and after it. There are no system entries if there are no
+// parentheses, rather than one empty entry.
+//
+// Use next() to get the entries; all entries are trimmed.
+func splitUA(ua string) (system fields, prods [2]fields) {
+	ua = strings.Trim(ua, "'") // Some clients wrap their UA in this.
+
+	s := strings.IndexByte(ua, '(')
+	e := strings.IndexByte(ua, ')')
+	if s == -1 || e < s {
+		return fields{done: true}, [2]fields{{s: ua, sep: ' '}, {done: true}}
+	}
+	return fields{s: ua[s+1 : e], sep: ';'}, [2]fields{
+		{s: strings.TrimSpace(ua[:s]), sep: ' '},
+		{s: strings.TrimSpace(ua[e+1:]), sep: ' '},
+	}
+}
+
+// fields splits a string on sep, like strings.Split().
+type fields struct {
+	s    string
+	sep  byte
+	done bool
+}
+
+func (f *fields) next() (string, bool) {
+	if f.done {
+		return "", false
+	}
+	i := strings.IndexByte(f.s, f.sep)
+	if i == -1 {
+		f.done = true
+		return strings.TrimSpace(f.s), true
+	}
+	v := f.s[:i]
+	f.s = f.s[i+1:]
+	return strings.TrimSpace(v), true
+}
+
 func isNumber(s byte) bool { return s >= 0x30 && s <= 0x39 }
 func isLetter(s byte) bool { return (s >= 0x41 && s <= 0x5a) || (s >= 0x61 && s <= 0x7a) }
 func after(s string, n int) string { // Safer string slicing.
@@ -564,15 +613,12 @@ func after(s string, n int) string { // Safer string slicing.
 // "1.1.6"      → "1.1.6"
 // "1.5.6BETA4" → "1.5.6"
 func toNumber(v string) string {
-	var b strings.Builder
-	for _, r := range v {
-		if !(r == '.' || (r >= '0' && r <= '9')) {
-			break
+	for i := 0; i < len(v); i++ {
+		if !(v[i] == '.' || isNumber(v[i])) {
+			return v[:i]
 		}
-		b.WriteRune(r)
 	}
-
-	return b.String()
+	return v
 }
 
 // Set maximum version level:
@@ -583,11 +629,19 @@ func maxVersion(v string, n int, trimZero bool) string {
 	v = toNumber(v)
 
 	if strings.Count(v, ".") >= n {
-		s := strings.Split(v, ".")
-		if trimZero && s[n-1] == "0" {
-			n -= 1
+		// Index of the n-th dot, and the one before that.
+		prev, end := -1, -1
+		for i := 0; i < n; i++ {
+			prev = end
+			end += 1 + strings.IndexByte(v[end+1:], '.')
+		}
+		if trimZero && v[prev+1:end] == "0" {
+			end = prev
+			if end == -1 {
+				end = 0
+			}
 		}
-		return strings.Join(s[:n], ".")
+		return v[:end]
 	}
 
 	if trimZero && strings.HasSuffix(v, ".0") {
@@ -595,3 +649,58 @@ func maxVersion(v string, n int, trimZero bool) string {
 	}
 	return v
 }
+
+// Like maxVersion(), but for versions with underscores such as "10_15_7", as
+// Apple uses. Common versions are looked up in underscoreVersions to avoid
+// allocating a new string.
+func maxVersionUnderscore(v string, n int) string {
+	l := 0
+	for ; l < len(v); l++ {
+		if !(v[l] == '.' || v[l] == '_' || isNumber(v[l])) {
+			break
+		}
+	}
+	v = v[:l]
+	if strings.IndexByte(v, '_') == -1 {
+		return maxVersion(v, n, false)
+	}
+
+	for i, seen := 0, 0; i < len(v); i++ {
+		if v[i] == '.' || v[i] == '_' {
+			if seen++; seen == n {
+				v = v[:i]
+				break
+			}
+		}
+	}
+
+	var buf [16]byte
+	if len(v) > len(buf) {
+		return strings.ReplaceAll(v, "_", ".")
+	}
+	b := buf[:len(v)]
+	for i := range b {
+		b[i] = v[i]
+		if b[i] == '_' {
+			b[i] = '.'
+		}
+	}
+	if d, ok := underscoreVersions[string(b)]; ok {
+		return d
+	}
+	return string(b)
+}
+
+// Dotted "major.minor" versions for maxVersionUnderscore().
+var underscoreVersions = func() map[string]string {
+	m := make(map[string]string, 41*22)
+	for major := 0; major <= 40; major++ {
+		ma := strconv.Itoa(major)
+		m[ma+"."] = ma + "."
+		for minor := 0; minor <= 20; minor++ {
+			v := ma + "." + strconv.Itoa(minor)
+			m[v] = v
+		}
+	}
+	return m
+}()
@@ -47,7 +47,7 @@ func TestParseUA(t *testing.T) {
 					if len(s) != 3 {
 						t.Fatalf("Malformed line: %q\n%#v", line, s)
 					}
-					got := ParseUA(UnshortenUA(s[2]))
+					got := ParseShortUA(s[2])
 					if got.Browser() == s[0] && got.OS() == s[1] {
 						return
 					}
@@ -64,6 +64,53 @@ func TestParseUA(t *testing.T) {
 	}
 }
 
+// testShortHeaders gets the User-Agent headers from the files in testdata/, as
+// they're stored: shortened with version 1 of ShortenUA(). It reads all files
+// if none are given.
+func testShortHeaders(t testing.TB, files ...string) []string {
+	t.Helper()
+	if len(files) == 0 {
+		all, err := ioutil.ReadDir("./testdata")
+		if err != nil {
+			t.Fatal(err)
+		}
+		for _, f := range all {
+			files = append(files, f.Name())
+		}
+	}
+
+	var list []string
+	for _, f := range files {
+		fp, err := os.Open("./testdata/" + f)
+		if err != nil {
+			t.Fatal(err)
+		}
+		scanner := bufio.NewScanner(fp)
+		for scanner.Scan() {
+			line := scanner.Text()
+			if line == "" || line[0] == '#' {
+				continue
+			}
+			list = append(list, line[strings.LastIndexByte(line, '\t')+1:])
+		}
+		fp.Close()
+		if err := scanner.Err(); err != nil {
+			t.Fatal(err)
+		}
+	}
+	return list
+}
+
+// testHeaders is like testShortHeaders(), but unshortens the headers.
+func testHeaders(t testing.TB, files ...string) []string {
+	t.Helper()
+	list := testShortHeaders(t, files...)
+	for i := range list {
+		list[i] = UnshortenUA(list[i])
+	}
+	return list
+}
+
 // Various junk data found with the fuzzer; just ensure it won't panic.
 func TestMalformed(t *testing.T) {
 	tests := []string{
@@ -86,6 +133,57 @@ func TestMalformed(t *testing.T) {
 	}
 }
 
+// parse() and parseAlloc() should give the same result for everything that
+// fits in propsBuf.
+func TestParseAlloc(t *testing.T) {
+	tests := append(testHeaders(t),
+		"", "'", "''", "/", "a b", " a  b ", "a) b", "a) (b", ")(", ")", "(", "()",
+		"(;)", "a (b;c) d", "a (b; c ) d (e) f", "'a (b) c'", "a (b) c)", "a ((b) c")
+
+	for _, tt := range tests {
+		b, ok := parse(tt)
+		if !ok {
+			t.Errorf("doesn't fit in propsBuf: %q", tt)
+			continue
+		}
+		p := parseAlloc(tt)
+
+		if got, want := fmt.Sprintf("%q", b.system[:b.nsys]), fmt.Sprintf("%q", p.system); got != want {
+			t.Errorf("system for %q\ngot:  %s\nwant: %s", tt, got, want)
+		}
+		if got, want := fmt.Sprintf("%q", b.products[:b.nprod]), fmt.Sprintf("%q", p.products); got != want {
+			t.Errorf("products for %q\ngot:  %s\nwant: %s", tt, got, want)
+		}
+	}
+}
+
+func TestReduced(t *testing.T) {
+	tests := []struct {
+		in   string
+		want bool
+	}{
+		{"Mozilla/5.0 (Linux; Android 10; K) AppleWebKit/537.36 (KHTML, like Gecko) Chrome/119.0.0.0 Mobile Safari/537.36", true},
+		{"Mozilla/5.0 (Windows NT 10.0; Win64; x64) AppleWebKit/537.36 (KHTML, like Gecko) Chrome/119.0.0.0 Safari/537.36", true},
+		{"Mozilla/5.0 (Macintosh; Intel Mac OS X 10_15_7) AppleWebKit/537.36 (KHTML, like Gecko) Chrome/119.0.0.0 Safari/537.36", true},
+		{"Mozilla/5.0 (X11; Linux x86_64) AppleWebKit/537.36 (KHTML, like Gecko) Chrome/119.0.0.0 Safari/537.36", true},
+		{"Mozilla/5.0 (X11; CrOS x86_64 14541.0.0) AppleWebKit/537.36 (KHTML, like Gecko) Chrome/119.0.0.0 Safari/537.36", true},
+
+		{"Mozilla/5.0 (Linux; Android 13; SM-G991B) AppleWebKit/537.36 (KHTML, like Gecko) Chrome/119.0.0.0 Mobile Safari/537.36", false},
+		{"Mozilla/5.0 (Windows NT 10.0; Win64; x64) AppleWebKit/537.36 (KHTML, like Gecko) Chrome/99.0.4844.51 Safari/537.36", false},
+		{"Mozilla/5.0 (Linux; Android 4.4.4; HUAWEI H891L Build/HuaweiH891L) AppleWebKit/537.36 (KHTML, like Gecko) Version/4.0 Chrome/33.0.0.0 Mobile Safari/537.36", false},
+		{"Mozilla/5.0 (Windows NT 10.0; Win64; x64; rv:120.0) Gecko/20100101 Firefox/120.0", false},
+	}
+
+	for i, tt := range tests {
+		t.Run(fmt.Sprintf("%d", i), func(t *testing.T) {
+			got := ParseUA(tt.in).Reduced
+			if got != tt.want {
+				t.Errorf("\ngot:  %t\nwant: %t", got, tt.want)
+			}
+		})
+	}
+}
+
 func TestString(t *testing.T) {
 	tests := []struct {
 		in   UserAgent
@@ -95,6 +193,7 @@ func TestString(t *testing.T) {
 		{UserAgent{OSName: "x"}, "x"},
 		{UserAgent{BrowserName: "x"}, "x"},
 		{UserAgent{BrowserName: "x", OSName: "y"}, "x on y"},
+		{UserAgent{BrowserName: "x", BrowserVersion: "1", OSName: "y", OSVersion: "2"}, "x 1 on y 2"},
 	}
 
 	for i, tt := range tests {
@@ -103,6 +202,50 @@ func TestString(t *testing.T) {
 			if got != tt.want {
 				t.Errorf("\ngot:  %q\nwant: %q", got, tt.want)
 			}
+
+			b := tt.in.AppendString([]byte("> "))
+			if string(b) != "> "+tt.want {
+				t.Errorf("AppendString\ngot:  %q\nwant: %q", b, "> "+tt.want)
+			}
+			b = tt.in.AppendOS(tt.in.AppendBrowser(nil))
+			if string(b) != tt.in.Browser()+tt.in.OS() {
+				t.Errorf("AppendBrowser/AppendOS\ngot:  %q\nwant: %q", b, tt.in.Browser()+tt.in.OS())
+			}
+		})
+	}
+}
+
+func TestMaxVersion(t *testing.T) {
+	tests := []struct {
+		in       string
+		n        int
+		trimZero bool
+		want     string
+	}{
+		{"", 2, false, ""},
+		{"75.0", 1, false, "75"},
+		{"5.0.6", 2, false, "5.0"},
+		{"5.0.6", 2, true, "5"},
+		{"0.1", 1, true, ""},
+		{"8.0", 2, true, "8"},
+		{"1.5.6BETA4", 3, false, "1.5.6"},
+		{"10_15_7", 2, false, "10.15"},
+		{"7_0 like Mac OS X", 2, false, "7.0"},
+		{"99_99_1", 2, false, "99.99"},
+		{"11.2_3", 2, false, "11.2"},
+	}
+
+	for i, tt := range tests {
+		t.Run(fmt.Sprintf("%d", i), func(t *testing.T) {
+			var got string
+			if strings.Contains(tt.in, "_") {
+				got = maxVersionUnderscore(tt.in, tt.n)
+			} else {
+				got = maxVersion(tt.in, tt.n, tt.trimZero)
+			}
+			if got != tt.want {
+				t.Errorf("\ngot:  %q\nwant: %q", got, tt.want)
+			}
 		})
 	}
 }
@@ -233,10 +376,18 @@ func BenchmarkParseUA(b *testing.B) {
 		if line == "" || line[0] == '#' {
 			continue
 		}
-		list = append(list, strings.Split(line, "\t")[2])
+		list = append(list, UnshortenUA(strings.Split(line, "\t")[2]))
+	}
+
+	i := 0
+	allocs := testing.AllocsPerRun(len(list), func() {
+		ParseUA(list[i%len(list)])
+		i++
+	})
+	if allocs > 0 {
+		b.Fatalf("ParseUA allocates: %v allocs/op", allocs)
 	}
 
-	b.ResetTimer()
 	b.ReportAllocs()
 	b.ResetTimer()
 	for n := 0; n < b.N; n++ {
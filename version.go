@@ -0,0 +1,131 @@
+package gadget
+
+import (
+	"strconv"
+	"strings"
+)
+
+// Versions that aren't a number, in the order they were released. These all
+// sort before numbered versions.
+var namedVersions = []string{"CE", "2000", "XP", "Vista"}
+
+// Version is a parsed browser or OS version.
+//
+// Versions that aren't a number, such as "XP", "Vista", or Linux distribution
+// names such as "Ubuntu", are stored in Name; Major, Minor, and Patch are 0 for
+// these.
+type Version struct {
+	Major, Minor, Patch int
+	Name                string
+}
+
+// ParseVersion parses a version such as "73", "5.1", or "14.0.3".
+//
+// Anything that doesn't start with a number is stored in Name. Anything after
+// the patch version is ignored. An empty string gives the zero Version; use
+// IsZero() to check for this, as it's not a known version.
+func ParseVersion(v string) Version {
+	v = strings.TrimSpace(v)
+	if v == "" {
+		return Version{}
+	}
+	if !isNumber(v[0]) {
+		return Version{Name: v}
+	}
+
+	var (
+		ver   Version
+		parts = [...]*int{&ver.Major, &ver.Minor, &ver.Patch}
+	)
+	for i, p := range strings.SplitN(toNumber(v), ".", 4) {
+		if i >= len(parts) {
+			break
+		}
+		n, err := strconv.Atoi(p)
+		if err != nil {
+			break
+		}
+		*parts[i] = n
+	}
+	return ver
+}
+
+// IsZero reports if this is the zero Version, for an empty or unknown version.
+func (v Version) IsZero() bool { return v == Version{} }
+
+// String shows the version, with the minor and patch version omitted if
+// they're 0.
+func (v Version) String() string {
+	switch {
+	case v.Name != "":
+		return v.Name
+	case v.Patch != 0:
+		return strconv.Itoa(v.Major) + "." + strconv.Itoa(v.Minor) + "." + strconv.Itoa(v.Patch)
+	case v.Minor != 0:
+		return strconv.Itoa(v.Major) + "." + strconv.Itoa(v.Minor)
+	default:
+		return strconv.Itoa(v.Major)
+	}
+}
+
+// Compare returns -1 if v is lower than o, 0 if they're equal, and 1 if v is
+// higher.
+//
+// Named versions sort before numbered ones; the Windows names CE, 2000, XP,
+// and Vista are ordered by release, and other names are compared as strings.
+func (v Version) Compare(o Version) int {
+	switch {
+	case v.Name != "" && o.Name != "":
+		vi, oi := namedIndex(v.Name), namedIndex(o.Name)
+		if vi != oi {
+			return cmpInt(vi, oi)
+		}
+		return strings.Compare(v.Name, o.Name)
+	case v.Name != "":
+		return -1
+	case o.Name != "":
+		return 1
+	}
+
+	if c := cmpInt(v.Major, o.Major); c != 0 {
+		return c
+	}
+	if c := cmpInt(v.Minor, o.Minor); c != 0 {
+		return c
+	}
+	return cmpInt(v.Patch, o.Patch)
+}
+
+// Less reports if v is lower than o.
+func (v Version) Less(o Version) bool { return v.Compare(o) < 0 }
+
+// BrowserVer gets the BrowserVersion as a Version.
+func (u UserAgent) BrowserVer() Version { return ParseVersion(u.BrowserVersion) }
+
+// OSVer gets the OSVersion as a Version.
+func (u UserAgent) OSVer() Version {
+	if u.OSName == "Windows" && namedIndex(u.OSVersion) < len(namedVersions) {
+		return Version{Name: u.OSVersion}
+	}
+	return ParseVersion(u.OSVersion)
+}
+
+func namedIndex(name string) int {
+	for i, n := range namedVersions {
+		if n == name {
+			return i
+		}
+	}
+	return len(namedVersions)
+}
+
+func cmpInt(a, b int) int {
+	switch {
+	case a < b:
+		return -1
+	case a > b:
+		return 1
+	default:
+		return 0
+	}
+}
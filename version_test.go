@@ -0,0 +1,94 @@
+package gadget
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+	"testing"
+)
+
+func TestParseVersion(t *testing.T) {
+	tests := []struct {
+		in   string
+		want Version
+		str  string
+	}{
+		{"", Version{}, "0"},
+		{"73", Version{Major: 73}, "73"},
+		{"5.1", Version{Major: 5, Minor: 1}, "5.1"},
+		{"13.0", Version{Major: 13}, "13"},
+		{"14.0.3", Version{Major: 14, Patch: 3}, "14.0.3"},
+		{"80.0.3987.132", Version{Major: 80, Patch: 3987}, "80.0.3987"},
+		{"1.5.6BETA4", Version{Major: 1, Minor: 5, Patch: 6}, "1.5.6"},
+		{"XP", Version{Name: "XP"}, "XP"},
+		{"Ubuntu", Version{Name: "Ubuntu"}, "Ubuntu"},
+	}
+
+	for i, tt := range tests {
+		t.Run(fmt.Sprintf("%d", i), func(t *testing.T) {
+			got := ParseVersion(tt.in)
+			if got != tt.want {
+				t.Errorf("\ngot:  %#v\nwant: %#v", got, tt.want)
+			}
+			if got.IsZero() != (tt.in == "") {
+				t.Errorf("IsZero() is %t", got.IsZero())
+			}
+			if got.String() != tt.str {
+				t.Errorf("String()\ngot:  %q\nwant: %q", got.String(), tt.str)
+			}
+		})
+	}
+}
+
+func TestVersionCompare(t *testing.T) {
+	tests := []struct {
+		a, b string
+		want int
+	}{
+		{"1", "1", 0},
+		{"1.0", "1", 0},
+		{"1", "2", -1},
+		{"2", "1", 1},
+		{"13.1", "14", -1},
+		{"14.0.1", "14", 1},
+		{"10", "9.9", 1},
+		{"XP", "Vista", -1},
+		{"Vista", "7", -1},
+		{"7", "XP", 1},
+		{"CE", "XP", -1},
+		{"Ubuntu", "Fedora", 1},
+		{"Vista", "Ubuntu", -1},
+	}
+
+	for i, tt := range tests {
+		t.Run(fmt.Sprintf("%d", i), func(t *testing.T) {
+			got := ParseVersion(tt.a).Compare(ParseVersion(tt.b))
+			if got != tt.want {
+				t.Errorf("\ngot:  %d\nwant: %d", got, tt.want)
+			}
+			if l := ParseVersion(tt.a).Less(ParseVersion(tt.b)); l != (tt.want < 0) {
+				t.Errorf("Less(): %t", l)
+			}
+		})
+	}
+}
+
+func TestOSVer(t *testing.T) {
+	var (
+		in   = []string{"10", "2000", "8.1", "XP", "7", "Vista", "CE", "11", "8"}
+		want = "CE 2000 XP Vista 7 8 8.1 10 11"
+	)
+	vers := make([]Version, 0, len(in))
+	for _, v := range in {
+		vers = append(vers, UserAgent{OSName: "Windows", OSVersion: v}.OSVer())
+	}
+	sort.Slice(vers, func(i, j int) bool { return vers[i].Less(vers[j]) })
+
+	got := make([]string, 0, len(vers))
+	for _, v := range vers {
+		got = append(got, v.String())
+	}
+	if strings.Join(got, " ") != want {
+		t.Errorf("\ngot:  %s\nwant: %s", strings.Join(got, " "), want)
+	}
+}